@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+var basicLandTypes = []string{"plains", "island", "swamp", "mountain", "forest", "wastes"}
+
+var basicsSort = []sortKey{{field: "released", desc: true}, {field: "name"}, {field: "number"}}
+
+func runBasics(args []string) error {
+	fs := flag.NewFlagSet("basics", flag.ExitOnError)
+	landType := fs.String("type", "", "only one basic land type: "+strings.Join(basicLandTypes, ", "))
+	fullArt := fs.Bool("full-art", false, "only full-art printings")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: basics [flags] <set code, set name, or artist>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	filter := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if filter == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	terms := []string{"t:basic", "unique:art"}
+	if *landType != "" {
+		lt := strings.ToLower(*landType)
+		if !containsString(basicLandTypes, lt) {
+			return fmt.Errorf("unknown basic land type %q", *landType)
+		}
+		if lt == "wastes" {
+			// Wastes has no land type, so match the name, which also finds
+			// Snow-Covered Wastes as t:island finds Snow-Covered Island.
+			terms = append(terms, lt)
+		} else {
+			terms = append(terms, "t:"+lt)
+		}
+	}
+	if *fullArt {
+		terms = append(terms, "is:full")
+	}
+
+	source := fmt.Sprintf("artist %s", filter)
+	if set, err := findSet(filter); err != nil {
+		return err
+	} else if set != nil {
+		terms = append(terms, "e:"+set.Code)
+		source = fmt.Sprintf("%s (%s)", set.Name, strings.ToUpper(set.Code))
+	} else {
+		terms = append(terms, fmt.Sprintf("a:%q", filter))
+	}
+
+	order := defaultSort
+	if order == nil {
+		order = basicsSort
+	}
+	cards, err := fetchCards(strings.Join(terms, " "), order)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		fmt.Printf("No basic lands found for %s\n", source)
+		return nil
+	}
+
+	fmt.Printf("%d basic land printings for %s\n\n", len(cards), source)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tSet\t#\tArtist\tPrice\tArt")
+	for _, card := range cards {
+		price := "-"
+		if card.Prices.USD != "" {
+			price = "$" + card.Prices.USD
+		} else if card.Prices.USDFoil != "" {
+			price = "$" + card.Prices.USDFoil + " foil"
+		}
+		art := card.ImageURIs["art_crop"]
+		if art == "" {
+			art = card.ImageURIs["small"]
+		}
+		name := card.Name
+		if card.FullArt {
+			name += " (full art)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", name, strings.ToUpper(card.Set), card.CollectorNumber, card.Artist, price, art)
+	}
+	return w.Flush()
+}
+
+// findSet matches a set code or name exactly, returning nil when the filter
+// is not a set.
+func findSet(filter string) (*scryfall.Set, error) {
+	sets, err := api.Sets()
+	if err != nil {
+		return nil, err
+	}
+	for i := range sets {
+		if strings.EqualFold(sets[i].Code, filter) || strings.EqualFold(sets[i].Name, filter) {
+			return &sets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+const (
+	botMaxCardRefs      = 3
+	botMaxSearchResults = 5
+)
+
+var cardRefPattern = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+type botCooldown struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+func newBotCooldown(interval time.Duration) *botCooldown {
+	return &botCooldown{interval: interval, last: make(map[string]time.Time)}
+}
+
+func (c *botCooldown) allow(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.last[key]; ok && time.Since(last) < c.interval {
+		return false
+	}
+	c.last[key] = time.Now()
+	return true
+}
+
+func botReplies(text string, maxLen int) []string {
+	text = strings.TrimSpace(text)
+	command, arg, _ := strings.Cut(text, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch command {
+	case "!card":
+		if arg == "" {
+			return nil
+		}
+		return []string{botCardReply(arg, maxLen)}
+	case "!price":
+		if arg == "" {
+			return nil
+		}
+		return []string{botPriceReply(arg, maxLen)}
+	case "!search":
+		if arg == "" {
+			return nil
+		}
+		return []string{botSearchReply(arg, maxLen)}
+	}
+
+	var replies []string
+	for _, ref := range cardRefPattern.FindAllStringSubmatch(text, botMaxCardRefs) {
+		name := strings.TrimSpace(ref[1])
+		if name != "" {
+			replies = append(replies, botCardReply(name, maxLen))
+		}
+	}
+	return replies
+}
+
+func botCardReply(name string, maxLen int) string {
+	card, err := api.GetCardByName(name)
+	if err != nil {
+		log.Printf("bot: lookup %q: %v", name, err)
+		return fmt.Sprintf("No card found for \"%s\"", name)
+	}
+	return formatBotCard(card, maxLen)
+}
+
+func botPriceReply(name string, maxLen int) string {
+	card, err := api.GetCardByName(name)
+	if err != nil {
+		log.Printf("bot: lookup %q: %v", name, err)
+		return fmt.Sprintf("No card found for \"%s\"", name)
+	}
+
+	parts := priceList(card.Prices)
+	if len(parts) == 0 {
+		return fmt.Sprintf("%s (%s): no price", card.Name, card.SetName)
+	}
+	return truncateRunes(fmt.Sprintf("%s (%s): %s", card.Name, card.SetName, strings.Join(parts, " · ")), maxLen)
+}
+
+// priceList lists every price Scryfall has for a card, starting with the
+// -currency one.
+func priceList(p scryfall.Prices) []string {
+	var parts []string
+	for _, c := range currencyOrder() {
+		switch c {
+		case "usd":
+			if p.USD != "" {
+				parts = append(parts, "$"+p.USD)
+			}
+			if p.USDFoil != "" {
+				parts = append(parts, "$"+p.USDFoil+" foil")
+			}
+		case "eur":
+			if p.EUR != "" {
+				parts = append(parts, "€"+p.EUR)
+			}
+		case "tix":
+			if p.TIX != "" {
+				parts = append(parts, p.TIX+" tix")
+			}
+		}
+	}
+	return parts
+}
+
+func botSearchReply(input string, maxLen int) string {
+	query, order, err := splitSortOption(input)
+	if err != nil {
+		return err.Error()
+	}
+	if order == nil {
+		order = defaultSort
+	}
+
+	cards, err := fetchCards(query, order)
+	if err != nil || len(cards) == 0 {
+		if err != nil {
+			log.Printf("bot: search %q: %v", query, err)
+		}
+		return fmt.Sprintf("No cards found for \"%s\"", query)
+	}
+
+	names := make([]string, 0, botMaxSearchResults)
+	for i := 0; i < len(cards) && i < botMaxSearchResults; i++ {
+		names = append(names, cards[i].Name)
+	}
+	reply := fmt.Sprintf("%d cards: %s", len(cards), strings.Join(names, ", "))
+	if len(cards) > botMaxSearchResults {
+		reply += fmt.Sprintf(" (+%d more)", len(cards)-botMaxSearchResults)
+	}
+	return truncateRunes(reply, maxLen)
+}
+
+func formatBotCard(card *scryfall.Card, maxLen int) string {
+	link := card.ScryfallURI
+	if u, err := url.Parse(link); err == nil {
+		u.RawQuery = ""
+		link = u.String()
+	}
+
+	head := card.Name
+	if card.ManaCost != "" {
+		head += " " + card.ManaCost
+	}
+	head += " | " + card.TypeLine
+	if front := card.Faces()[0]; front.Power != "" && front.Toughness != "" {
+		head += fmt.Sprintf(" %s/%s", front.Power, front.Toughness)
+	}
+
+	text := strings.Join(strings.Fields(strings.ReplaceAll(card.Text(), "\n", " / ")), " ")
+	text = truncateRunes(text, maxLen-utf8.RuneCountInString(head+link)-len(" |  | "))
+
+	if text == "" {
+		return fmt.Sprintf("%s | %s", head, link)
+	}
+	return fmt.Sprintf("%s | %s | %s", head, text, link)
+}
+
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	if max < 1 {
+		return ""
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// priceLabel is the -currency price, or the first other one there is.
+func priceLabel(p scryfall.Prices) string {
+	for _, c := range currencyOrder() {
+		switch {
+		case c == "usd" && p.USD != "":
+			return "$" + p.USD
+		case c == "eur" && p.EUR != "":
+			return "€" + p.EUR
+		case c == "tix" && p.TIX != "":
+			return p.TIX + " tix"
+		}
+	}
+	return "no price"
+}
+
+var currencies = []string{"usd", "eur", "tix"}
+
+// currency is the price shown first, set by -currency.
+var currency = "usd"
+
+func currencyOrder() []string {
+	order := []string{currency}
+	for _, c := range currencies {
+		if c != currency {
+			order = append(order, c)
+		}
+	}
+	return order
+}
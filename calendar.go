@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+const (
+	dateLayout    = "2006-01-02"
+	icalDateStamp = "20060102"
+	icalTimeStamp = "20060102T150405Z"
+	icalLineLimit = 75
+)
+
+var calendarSkipTypes = map[string]bool{
+	"token":       true,
+	"memorabilia": true,
+	"promo":       true,
+	"minigame":    true,
+}
+
+var prereleaseSetTypes = map[string]bool{
+	"core":      true,
+	"expansion": true,
+}
+
+type calendarEvent struct {
+	uid     string
+	summary string
+	detail  string
+	link    string
+	start   time.Time
+	days    int
+}
+
+func runCalendar(args []string) error {
+	fs := flag.NewFlagSet("calendar", flag.ExitOnError)
+	icalPath := fs.String("ical", "", "write the calendar to this .ics file instead of printing it")
+	from := fs.String("from", time.Now().Format(dateLayout), "only include sets released on or after this date (YYYY-MM-DD)")
+	digital := fs.Bool("digital", false, "include digital-only sets")
+	fs.Parse(args)
+
+	since, err := time.Parse(dateLayout, *from)
+	if err != nil {
+		return fmt.Errorf("invalid -from date %q: %w", *from, err)
+	}
+
+	sets, err := api.Sets()
+	if err != nil {
+		return err
+	}
+
+	events := calendarEvents(sets, since, *digital)
+	if len(events) == 0 {
+		fmt.Println("No releases found")
+		return nil
+	}
+
+	if *icalPath == "" {
+		for _, e := range events {
+			fmt.Printf("%s  %s\n", e.start.Format(dateLayout), e.summary)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(*icalPath, []byte(renderICal(events, time.Now())), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *icalPath, err)
+	}
+	fmt.Printf("Wrote %d events to %s\n", len(events), *icalPath)
+	return nil
+}
+
+func calendarEvents(sets []scryfall.Set, since time.Time, digital bool) []calendarEvent {
+	var events []calendarEvent
+	for _, set := range sets {
+		if calendarSkipTypes[set.SetType] || (set.Digital && !digital) {
+			continue
+		}
+		released, err := time.Parse(dateLayout, set.ReleasedAt)
+		if err != nil || released.Before(since) {
+			continue
+		}
+
+		detail := fmt.Sprintf("%s (%s), %s set", set.Name, strings.ToUpper(set.Code), strings.ReplaceAll(set.SetType, "_", " "))
+		if set.CardCount > 0 {
+			detail += fmt.Sprintf(", %d cards", set.CardCount)
+		}
+
+		events = append(events, calendarEvent{
+			uid:     set.Code + "-release@mtg-go-search",
+			summary: fmt.Sprintf("%s release", set.Name),
+			detail:  detail,
+			link:    set.ScryfallURI,
+			start:   released,
+			days:    1,
+		})
+
+		// Scryfall has no prerelease dates; tabletop prereleases are held
+		// the weekend before a premier set's release.
+		if prereleaseSetTypes[set.SetType] && !set.Digital {
+			events = append(events, calendarEvent{
+				uid:     set.Code + "-prerelease@mtg-go-search",
+				summary: fmt.Sprintf("%s prerelease (estimated)", set.Name),
+				detail:  detail,
+				link:    set.ScryfallURI,
+				start:   released.AddDate(0, 0, -7),
+				days:    3,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].start.Before(events[j].start)
+	})
+	return events
+}
+
+func renderICal(events []calendarEvent, now time.Time) string {
+	var b strings.Builder
+
+	writeLine := func(line string) {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//cloudsmyth//mtg-go-search//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("X-WR-CALNAME:MTG Releases")
+
+	stamp := now.UTC().Format(icalTimeStamp)
+	for _, e := range events {
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + e.uid)
+		writeLine("DTSTAMP:" + stamp)
+		writeLine("DTSTART;VALUE=DATE:" + e.start.Format(icalDateStamp))
+		writeLine("DTEND;VALUE=DATE:" + e.start.AddDate(0, 0, e.days).Format(icalDateStamp))
+		writeLine("SUMMARY:" + escapeICalText(e.summary))
+		writeLine("DESCRIPTION:" + escapeICalText(e.detail))
+		if e.link != "" {
+			writeLine("URL:" + e.link)
+		}
+		writeLine("TRANSP:TRANSPARENT")
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+	return b.String()
+}
+
+func escapeICalText(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return replacer.Replace(s)
+}
+
+func foldICalLine(line string) string {
+	if len(line) <= icalLineLimit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > icalLineLimit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/decks"
+)
+
+// collectionVersion is written to the collection file so a later release
+// can tell which layout it is reading.
+const collectionVersion = 1
+
+type collection struct {
+	Version int          `json:"version"`
+	Cards   []ownedCards `json:"cards"`
+}
+
+// ownedCards is a stack of copies of one card, or of one printing when set
+// and number are given.
+type ownedCards struct {
+	Name   string `json:"name"`
+	Set    string `json:"set,omitempty"`
+	Number string `json:"number,omitempty"`
+	Foil   bool   `json:"foil,omitempty"`
+	Count  int    `json:"count"`
+}
+
+// collectionFormats are the deck writers that need no lookups, for export.
+var collectionFormats = []string{"moxfield", "arena", "mtgo", "txt"}
+
+// owned maps lowercased card names to the copies in the collection, for the
+// annotations in the interactive browser. It is nil when nothing is owned.
+var owned map[string]int
+
+func collectionPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "mtg-go-search", "collection.json"), nil
+}
+
+func runCollection(args []string) error {
+	fs := flag.NewFlagSet("collection", flag.ExitOnError)
+	path := fs.String("file", "", "collection file (defaults to collection.json in the config directory)")
+	asCSV := fs.Bool("csv", false, "print tables as CSV")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: collection [flags] <command>")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  add <card> [count]       add copies, e.g. \"Lightning Bolt\" 4 or \"Lightning Bolt (M10) 146\"")
+		fmt.Fprintln(out, "  remove <card> [count]    remove copies, one by default")
+		fmt.Fprintln(out, "  list                     every card owned")
+		fmt.Fprintln(out, "  value                    USD value of the collection")
+		fmt.Fprintln(out, "  import <file>            add every card in a decklist or Moxfield CSV")
+		fmt.Fprintln(out, "  export [format]          write the collection as moxfield (CSV, the default), arena, mtgo, or txt")
+		fmt.Fprintln(out, "")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *path == "" {
+		p, err := collectionPath()
+		if err != nil {
+			return err
+		}
+		*path = p
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// Changes read, modify, and write the file, so they hold a lock for the
+	// whole time in case another process is changing it too.
+	if slices.Contains([]string{"add", "remove", "import"}, rest[0]) {
+		if err := os.MkdirAll(filepath.Dir(*path), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(*path), err)
+		}
+		unlock, err := lockFile(*path + ".lock")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	c, err := loadCollection(*path)
+	if err != nil {
+		return err
+	}
+
+	switch rest[0] {
+	case "add", "remove":
+		if len(rest) < 2 || len(rest) > 3 {
+			return fmt.Errorf("usage: collection %s <card> [count]", rest[0])
+		}
+		e, err := collectionEntry(rest[1:])
+		if err != nil {
+			return err
+		}
+		if rest[0] == "add" {
+			err = c.add(e)
+		} else {
+			err = c.remove(e)
+		}
+		if err != nil {
+			return err
+		}
+		return c.save(*path)
+
+	case "list":
+		var rows [][]string
+		for _, o := range c.Cards {
+			rows = append(rows, []string{strconv.Itoa(o.Count), o.Name, strings.ToUpper(o.Set), o.Number, foilLabel(o.Foil)})
+		}
+		return writeTable(*asCSV, []string{"Count", "Name", "Set", "Number", "Foil"}, rows)
+
+	case "value":
+		return c.value(*asCSV)
+
+	case "import":
+		if len(rest) != 2 {
+			return fmt.Errorf("usage: collection import <file>")
+		}
+		if err := c.importDeck(rest[1]); err != nil {
+			return err
+		}
+		return c.save(*path)
+
+	case "export":
+		format := "moxfield"
+		if len(rest) == 2 {
+			format = rest[1]
+		}
+		if !slices.Contains(collectionFormats, format) || len(rest) > 2 {
+			return fmt.Errorf("usage: collection export [%s]", strings.Join(collectionFormats, "|"))
+		}
+		return deckWriters[format](os.Stdout, "Collection", c.entries())
+	}
+
+	return fmt.Errorf("unknown collection command %q", rest[0])
+}
+
+// collectionEntry reads the card and count arguments of add and remove. The
+// card is read like a decklist line, so it can name a printing.
+func collectionEntry(args []string) (deckEntry, error) {
+	line, err := decks.ParseLine(args[0])
+	if err != nil {
+		return deckEntry{}, err
+	}
+	e := fromCard(line.Card)
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return e, fmt.Errorf("invalid count %q", args[1])
+		}
+		e.count = n
+	}
+	return e, nil
+}
+
+func loadCollection(path string) (*collection, error) {
+	c := &collection{Version: collectionVersion}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if c.Version > collectionVersion {
+		return nil, fmt.Errorf("%s was written by a newer release (version %d)", path, c.Version)
+	}
+	c.Version = collectionVersion
+	return c, nil
+}
+
+func (c *collection) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode collection: %w", err)
+	}
+	return replaceFile(path, data)
+}
+
+// add looks the card up so the collection keeps Scryfall's spelling of the
+// name, then adds to the matching stack or starts a new one.
+func (c *collection) add(e deckEntry) error {
+	entries := []deckEntry{e}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+	if entries[0].card == nil {
+		return fmt.Errorf("unknown card %q", e.name)
+	}
+	e.name = entries[0].card.Name
+	c.put(e)
+	fmt.Printf("You own %d %s\n", c.copies(e.name), e.name)
+	return nil
+}
+
+// put adds e's copies to the matching stack or starts a new one.
+func (c *collection) put(e deckEntry) {
+	if i := c.find(e); i >= 0 {
+		c.Cards[i].Count += e.count
+		return
+	}
+	c.Cards = append(c.Cards, ownedCards{Name: e.name, Set: e.set, Number: e.number, Foil: e.foil, Count: e.count})
+	sort.SliceStable(c.Cards, func(i, j int) bool { return strings.ToLower(c.Cards[i].Name) < strings.ToLower(c.Cards[j].Name) })
+}
+
+// importDeck adds every card in a deck file, outside its maybeboard, looking
+// them all up in a few requests. Unknown cards are reported and skipped.
+func (c *collection) importDeck(path string) error {
+	entries, problems, err := loadDeck(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", p.File, p.Line, p.Severity, p.Message)
+	}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+	added := 0
+	for _, e := range entries {
+		if e.section == maybeboardSection {
+			continue
+		}
+		if e.card == nil {
+			fmt.Fprintf(os.Stderr, "%s: skipped unknown card %q\n", path, e.name)
+			continue
+		}
+		e.name = e.card.Name
+		c.put(e)
+		added += e.count
+	}
+	fmt.Printf("Added %d cards from %s\n", added, path)
+	return nil
+}
+
+// remove takes copies from the stack matching e, or from any printing of the
+// card when e does not name one.
+func (c *collection) remove(e deckEntry) error {
+	i := c.find(e)
+	if i < 0 && e.set == "" {
+		i = slices.IndexFunc(c.Cards, func(o ownedCards) bool { return sameCardName(o.Name, e.name) })
+	}
+	if i < 0 {
+		return fmt.Errorf("%q is not in the collection", e.name)
+	}
+	o := &c.Cards[i]
+	if e.count > o.Count {
+		return fmt.Errorf("cannot remove %d copies of %s; the collection has %d", e.count, o.Name, o.Count)
+	}
+	o.Count -= e.count
+	name := o.Name
+	if o.Count == 0 {
+		c.Cards = slices.Delete(c.Cards, i, i+1)
+	}
+	fmt.Printf("You own %d %s\n", c.copies(name), name)
+	return nil
+}
+
+// find returns the index of the stack for e's card and printing, or -1.
+func (c *collection) find(e deckEntry) int {
+	return slices.IndexFunc(c.Cards, func(o ownedCards) bool {
+		return sameCardName(o.Name, e.name) && o.Set == e.set && o.Number == e.number && o.Foil == e.foil
+	})
+}
+
+// copies counts every printing of a card.
+func (c *collection) copies(name string) int {
+	n := 0
+	for _, o := range c.Cards {
+		if sameCardName(o.Name, name) {
+			n += o.Count
+		}
+	}
+	return n
+}
+
+// sameCardName also matches a double-faced card by its front face.
+func sameCardName(full, name string) bool {
+	if strings.EqualFold(full, name) {
+		return true
+	}
+	front, _, ok := strings.Cut(full, " // ")
+	return ok && strings.EqualFold(front, name)
+}
+
+func (c *collection) entries() []deckEntry {
+	entries := make([]deckEntry, len(c.Cards))
+	for i, o := range c.Cards {
+		entries[i] = deckEntry{count: o.Count, name: o.Name, set: o.Set, number: o.Number, foil: o.Foil, section: mainSection}
+	}
+	return entries
+}
+
+// value prices every stack at its printing when it names one, and otherwise at
+// the printing Scryfall returns for the name.
+func (c *collection) value(asCSV bool) error {
+	entries := c.entries()
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+	var rows [][]string
+	for _, e := range entries {
+		price, total := deckEntryPrice(e), ""
+		if p, err := strconv.ParseFloat(price, 64); err == nil {
+			total = fmt.Sprintf("%.2f", p*float64(e.count))
+		}
+		rows = append(rows, []string{strconv.Itoa(e.count), e.name, strings.ToUpper(e.set), foilLabel(e.foil), price, total})
+	}
+	if err := writeTable(asCSV, []string{"Count", "Name", "Set", "Foil", "USD", "Total"}, rows); err != nil {
+		return err
+	}
+	if asCSV || len(rows) == 0 {
+		return nil
+	}
+
+	usd, unpriced := deckPrice(entries)
+	fmt.Printf("\nTotal: $%.2f", usd)
+	if unpriced > 0 {
+		fmt.Printf(" (%d cards without a price)", unpriced)
+	}
+	fmt.Println()
+	return nil
+}
+
+// loadOwned fills in owned from the default collection file.
+func loadOwned() error {
+	path, err := collectionPath()
+	if err != nil {
+		return err
+	}
+	c, err := loadCollection(path)
+	if err != nil {
+		return err
+	}
+	for _, o := range c.Cards {
+		if owned == nil {
+			owned = map[string]int{}
+		}
+		owned[strings.ToLower(o.Name)] += o.Count
+	}
+	return nil
+}
+
+// ownedCopies returns how many copies of a card the collection holds.
+func ownedCopies(name string) int {
+	if n, ok := owned[strings.ToLower(name)]; ok {
+		return n
+	}
+	if front, _, ok := strings.Cut(name, " // "); ok {
+		return owned[strings.ToLower(front)]
+	}
+	return 0
+}
+
+func foilLabel(foil bool) string {
+	if foil {
+		return "foil"
+	}
+	return ""
+}
+
+func ownedLabel(n int) string {
+	if n == 1 {
+		return "you own 1 copy"
+	}
+	return fmt.Sprintf("you own %d copies", n)
+}
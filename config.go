@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/render"
+)
+
+// config holds default values for global flags, keyed by flag name, such as
+// {"output": "plain", "legality": "modern,commander"}. Flags given on the
+// command line win.
+type config map[string]string
+
+// cacheDir replaces the default cache directory when set by -cache-dir.
+var cacheDir string
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find config directory: %w", err)
+	}
+	return filepath.Join(dir, "mtg-go-search", "config.json"), nil
+}
+
+func loadConfig(path string) (config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var c config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c config) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyConfig sets each flag in the config file that was not given on the
+// command line.
+func applyConfig(path string, explicit map[string]bool) error {
+	c, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	for name, value := range c {
+		if flag.Lookup(name) == nil {
+			return fmt.Errorf("%s: unknown setting %q", path, name)
+		}
+		if explicit[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("%s: invalid %s %q: %w", path, name, value, err)
+		}
+	}
+	return nil
+}
+
+// firstRun reports whether the interactive browser is about to open for
+// someone who has never saved settings, so setup should ask for them first.
+func firstRun(path string) bool {
+	_, err := os.Stat(path)
+	return errors.Is(err, os.ErrNotExist) && flag.NArg() == 0 && stdinIsTerminal() && stdoutIsTerminal()
+}
+
+func runSetup(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: setup (it asks for each setting in turn)")
+	}
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	ended := false
+	ask := func(prompt, current string) string {
+		fmt.Printf("%s [%s]: ", prompt, current)
+		if ended || !in.Scan() {
+			ended = true
+			fmt.Println()
+			return current
+		}
+		if answer := strings.TrimSpace(in.Text()); answer != "" {
+			return answer
+		}
+		return current
+	}
+	choose := func(prompt, current string, choices []string) string {
+		for {
+			answer := strings.ToLower(ask(fmt.Sprintf("%s (%s)", prompt, strings.Join(choices, ", ")), current))
+			if slices.Contains(choices, answer) || ended {
+				return answer
+			}
+			fmt.Printf("%q is not one of the choices.\n", answer)
+		}
+	}
+	yes := func(prompt string, current bool) bool {
+		def := "n"
+		if current {
+			def = "y"
+		}
+		return strings.HasPrefix(strings.ToLower(choose(prompt, def, []string{"y", "n", "yes", "no"})), "y")
+	}
+	value := func(name string) string { return flag.Lookup(name).Value.String() }
+
+	fmt.Println("Choose your settings; press Enter to keep the value in brackets.")
+	fmt.Println()
+	c := config{}
+	bulk, err := bulkPath()
+	if err != nil {
+		return err
+	}
+	c["cache-dir"] = ask("Where to keep downloaded card data", filepath.Dir(bulk))
+	if err := flag.Set("cache-dir", c["cache-dir"]); err != nil {
+		return err
+	}
+	bulk, _ = bulkPath()
+	_, statErr := os.Stat(bulk)
+	hasBulk := statErr == nil
+	download := yes("Download Scryfall's card data now for offline searches (about 500 MB)", false)
+	if download || hasBulk {
+		c["offline"] = fmt.Sprint(yes("Search the downloaded data instead of the Scryfall API by default", offline))
+	}
+	c["currency"] = choose("Currency to show prices in", value("currency"), currencies)
+	formats := strings.Split(strings.ToLower(ask("Formats you play, comma-separated", value("legality"))), ",")
+	for i := range formats {
+		formats[i] = strings.TrimSpace(formats[i])
+	}
+	c["legality"] = strings.Join(slices.DeleteFunc(formats, func(f string) bool { return f == "" }), ",")
+	c["output"] = choose("Output style for searches given on the command line", value("output"), render.Formats)
+
+	if err := c.save(path); err != nil {
+		return err
+	}
+	fmt.Printf("\nSaved to %s; edit it or run setup again to change these.\n", path)
+	if download {
+		fmt.Println()
+		return runSync(nil)
+	}
+	return nil
+}
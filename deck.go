@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/decks"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+const (
+	mainSection       = decks.Main
+	sideboardSection  = decks.Sideboard
+	commanderSection  = decks.Commander
+	companionSection  = decks.Companion
+	maybeboardSection = decks.Maybeboard
+)
+
+type deckEntry struct {
+	line    int
+	count   int
+	name    string
+	set     string
+	number  string
+	section string
+	// category is a grouping within a section, such as "Ramp", from a
+	// "// Ramp" header or a tag on the line.
+	category string
+	foil     bool
+	card     *scryfall.Card
+}
+
+type deckProblem struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Card     string `json:"card,omitempty"`
+	Message  string `json:"message"`
+}
+
+var deckCommands = map[string]func(args []string) error{
+	"lint":     runDeckLint,
+	"hash":     runDeckHash,
+	"convert":  runDeckConvert,
+	"show":     runDeckShow,
+	"grep":     runDeckGrep,
+	"check":    runDeckCheck,
+	"validate": runDeckValidate,
+	"stats":    runDeckStats,
+}
+
+func runDeck(args []string) error {
+	if len(args) == 0 || deckCommands[args[0]] == nil {
+		var names []string
+		for name := range deckCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Usage: deck <%s> [flags] <file>\n", strings.Join(names, "|"))
+		os.Exit(2)
+	}
+	return deckCommands[args[0]](args[1:])
+}
+
+func loadDeck(path string) ([]deckEntry, []deckProblem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	entries, problems, err := deckParser(path)(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	for i := range problems {
+		problems[i].File = path
+	}
+	return entries, problems, nil
+}
+
+// parseDeck reads a plain-text decklist with decks.ReadText. Lines that
+// cannot be read are reported as warnings and skipped.
+func parseDeck(r io.Reader) ([]deckEntry, []deckProblem, error) {
+	read, skipped, err := decks.ReadText(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := make([]deckEntry, len(read))
+	for i, e := range read {
+		entries[i] = fromCard(e.Card)
+		entries[i].line, entries[i].category = e.Line, e.Category
+	}
+	var problems []deckProblem
+	for _, p := range skipped {
+		problems = append(problems, deckProblem{Line: p.Line, Severity: "warning", Message: "skipped line: " + p.Message})
+	}
+	return entries, problems, nil
+}
+
+// resolveDeck looks every entry up on Scryfall in as few requests as
+// possible, setting card on each entry that matched.
+func resolveDeck(entries []deckEntry) error {
+	var ids []scryfall.Identifier
+	seen := map[scryfall.Identifier]bool{}
+	for _, e := range entries {
+		id := e.identifier()
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	cards, _, err := api.Collection(ids)
+	if err != nil {
+		return err
+	}
+
+	byName := map[string]*scryfall.Card{}
+	byPrinting := map[string]*scryfall.Card{}
+	for i := range cards {
+		c := &cards[i]
+		byPrinting[c.Set+"/"+c.CollectorNumber] = c
+		byName[strings.ToLower(c.Name)] = c
+		if front, _, ok := strings.Cut(c.Name, " // "); ok {
+			byName[strings.ToLower(front)] = c
+		}
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		if e.number != "" {
+			e.card = byPrinting[e.set+"/"+e.number]
+		}
+		if e.card == nil {
+			e.card = byName[strings.ToLower(e.name)]
+		}
+	}
+	return nil
+}
+
+func (e deckEntry) identifier() scryfall.Identifier {
+	if e.set != "" && e.number != "" {
+		return scryfall.Identifier{Set: e.set, CollectorNumber: e.number}
+	}
+	return scryfall.Identifier{Name: e.name, Set: e.set}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var deckSectionOrder = []string{mainSection, commanderSection, companionSection, sideboardSection, maybeboardSection}
+
+type deckGroup struct {
+	section  string
+	category string
+	entries  []deckEntry
+}
+
+// deckGroups splits entries by section, then by category in the order the
+// categories first appear. Cards without a category come first.
+func deckGroups(entries []deckEntry) []deckGroup {
+	var groups []deckGroup
+	for _, section := range deckSectionOrder {
+		index := map[string]int{}
+		start := len(groups)
+		for _, e := range entries {
+			if e.section != section {
+				continue
+			}
+			i, ok := index[e.category]
+			if !ok {
+				i = len(groups)
+				index[e.category] = i
+				groups = append(groups, deckGroup{section: section, category: e.category})
+			}
+			groups[i].entries = append(groups[i].entries, e)
+		}
+		if i, ok := index[""]; ok && i != start {
+			uncategorized := groups[i]
+			copy(groups[start+1:i+1], groups[start:i])
+			groups[start] = uncategorized
+		}
+	}
+	return groups
+}
+
+func runDeckShow(args []string) error {
+	fs := flag.NewFlagSet("deck show", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck show <file>")
+		fmt.Fprintln(fs.Output(), "Prints the deck grouped by section and category, with card counts and average mana value for each group.")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	entries, problems, err := loadDeck(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", p.File, p.Line, p.Severity, p.Message)
+	}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+
+	section := ""
+	for _, g := range deckGroups(entries) {
+		if g.section != section {
+			if section != "" {
+				fmt.Println()
+			}
+			section = g.section
+			total := 0
+			for _, e := range entries {
+				if e.section == section {
+					total += e.count
+				}
+			}
+			fmt.Printf("%s (%d)\n", strings.ToUpper(section[:1])+section[1:], total)
+		}
+
+		indent := "  "
+		if g.category != "" {
+			fmt.Printf("  %s (%s)\n", g.category, groupSummary(g.entries))
+			indent = "    "
+		}
+		for _, e := range g.entries {
+			fmt.Printf("%s%d %s\n", indent, e.count, e.name)
+		}
+	}
+	return nil
+}
+
+// groupSummary counts copies, averages mana value over nonland copies, and
+// adds up USD prices when -prices is set. Cards that were not found on
+// Scryfall only add to the count.
+func groupSummary(entries []deckEntry) string {
+	count, spells := 0, 0
+	var mv, usd float64
+	for _, e := range entries {
+		count += e.count
+		if e.card == nil {
+			continue
+		}
+		if !strings.Contains(e.card.TypeLine, "Land") {
+			spells += e.count
+			mv += e.card.CMC * float64(e.count)
+		}
+		if p, err := strconv.ParseFloat(e.card.Prices.USD, 64); err == nil {
+			usd += p * float64(e.count)
+		}
+	}
+
+	parts := []string{fmt.Sprintf("%d cards", count)}
+	if count == 1 {
+		parts[0] = "1 card"
+	}
+	if spells > 0 {
+		parts = append(parts, fmt.Sprintf("avg mana value %.2f", mv/float64(spells)))
+	}
+	if showPrices {
+		parts = append(parts, fmt.Sprintf("$%.2f", usd))
+	}
+	return strings.Join(parts, ", ")
+}
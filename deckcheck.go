@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxCurve is the last mana curve bucket, which also holds everything above.
+const maxCurve = 7
+
+func runDeckCheck(args []string) error {
+	fs := flag.NewFlagSet("deck check", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck check <file>")
+		fmt.Fprintln(fs.Output(), "Looks up every card in a few Scryfall requests and reports unknown names, the total price, and the mana curve.")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	entries, problems, err := loadDeck(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", p.File, p.Line, p.Severity, p.Message)
+	}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+
+	unknown := 0
+	for _, e := range entries {
+		if e.card == nil {
+			fmt.Printf("%s:%d: unknown card %q\n", path, e.line, e.name)
+			unknown++
+		}
+	}
+	if unknown > 0 {
+		fmt.Println()
+	}
+
+	var b strings.Builder
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[e.section] += e.count
+	}
+	fmt.Fprintf(&b, "%d cards", counts[mainSection]+counts[commanderSection]+counts[companionSection])
+	for _, section := range []string{sideboardSection, maybeboardSection} {
+		if counts[section] > 0 {
+			fmt.Fprintf(&b, ", %d in the %s", counts[section], section)
+		}
+	}
+	b.WriteString("\n")
+
+	usd, unpriced := deckPrice(entries)
+	fmt.Fprintf(&b, "Total price: $%.2f", usd)
+	if unpriced > 0 {
+		fmt.Fprintf(&b, " (%d cards without a price)", unpriced)
+	}
+	b.WriteString("\n\n")
+
+	rows, spells := manaCurve(entries)
+	writeCountSection(&b, "Mana curve (nonland, main deck)", rows, spells)
+	fmt.Print(b.String())
+
+	if unknown > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// deckPrice adds up the USD price of every copy outside the maybeboard.
+func deckPrice(entries []deckEntry) (float64, int) {
+	var usd float64
+	unpriced := 0
+	for _, e := range entries {
+		if e.section == maybeboardSection {
+			continue
+		}
+		p, err := strconv.ParseFloat(deckEntryPrice(e), 64)
+		if err != nil {
+			unpriced += e.count
+			continue
+		}
+		usd += p * float64(e.count)
+	}
+	return usd, unpriced
+}
+
+// inMainDeck reports whether an entry is played, as opposed to sitting in
+// the sideboard or maybeboard.
+func inMainDeck(e deckEntry) bool {
+	return e.section == mainSection || e.section == commanderSection || e.section == companionSection
+}
+
+// manaCurve counts nonland copies in the main deck by mana value, returning
+// the rows and the number of copies counted.
+func manaCurve(entries []deckEntry) ([]countRow, int) {
+	curve := make([]int, maxCurve+1)
+	spells := 0
+	for _, e := range entries {
+		if e.card == nil || !inMainDeck(e) || strings.Contains(e.card.Faces()[0].TypeLine, "Land") {
+			continue
+		}
+		curve[min(int(e.card.CMC), maxCurve)] += e.count
+		spells += e.count
+	}
+
+	rows := make([]countRow, len(curve))
+	for mv, n := range curve {
+		rows[mv] = countRow{strconv.Itoa(mv), n}
+	}
+	rows[maxCurve].label += "+"
+	return rows, spells
+}
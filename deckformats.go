@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/decks"
+)
+
+var deckWriters = map[string]func(w io.Writer, name string, entries []deckEntry) error{
+	"txt":      writeTextDeck,
+	"cod":      writeCockatriceDeck,
+	"dck":      writeXMageDeck,
+	"forge":    writeForgeDeck,
+	"untap":    writeUntapDeck,
+	"arena":    toDecks(decks.WriteArena),
+	"mtgo":     toDecks(decks.WriteMTGO),
+	"moxfield": toDecks(decks.WriteMoxfield),
+}
+
+// xmageLinePattern matches XMage's "4 [M10:146] Lightning Bolt".
+var xmageLinePattern = regexp.MustCompile(`^(\d+)\s+\[([^\]:]+):([^\]]+)\]\s*(.+)$`)
+
+var (
+	// forgeHeaderPattern matches a Forge section header such as "[Main]".
+	forgeHeaderPattern = regexp.MustCompile(`^\[([A-Za-z ]+)\]$`)
+	// forgeLinePattern matches Forge's "4 Lightning Bolt|M10" and
+	// "4 Lightning Bolt|M10|2", where the set and art index are optional.
+	forgeLinePattern = regexp.MustCompile(`^(\d+)\s+([^|]+?)(?:\|([A-Za-z0-9]+)(?:\|\S*)?)?$`)
+)
+
+type cockatriceDeck struct {
+	XMLName  xml.Name         `xml:"cockatrice_deck"`
+	Version  string           `xml:"version,attr"`
+	Name     string           `xml:"deckname"`
+	Comments string           `xml:"comments"`
+	Zones    []cockatriceZone `xml:"zone"`
+}
+
+type cockatriceZone struct {
+	Name  string           `xml:"name,attr"`
+	Cards []cockatriceCard `xml:"card"`
+}
+
+type cockatriceCard struct {
+	Number int    `xml:"number,attr"`
+	Name   string `xml:"name,attr"`
+}
+
+func runDeckConvert(args []string) error {
+	fs := flag.NewFlagSet("deck convert", flag.ExitOnError)
+	to := fs.String("to", "txt", "output format: txt, cod (Cockatrice), dck (XMage), forge, untap (untap.in paste), arena, mtgo, or moxfield (CSV)")
+	name := fs.String("name", "", "deck name to write (defaults to the file name)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck convert [flags] <file>")
+		fmt.Fprintln(fs.Output(), "Reads a text, Cockatrice .cod, XMage .dck, MTGO .dek, or Moxfield .csv deck and writes it to stdout in another format.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	write := deckWriters[*to]
+	if fs.NArg() != 1 || write == nil {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	if *name == "" {
+		*name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	entries, problems, err := loadDeck(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", p.File, p.Line, p.Severity, p.Message)
+	}
+
+	// XMage and Forge name a printing for every card, so look up the ones without.
+	if *to == "dck" || *to == "forge" {
+		if err := resolveDeck(entries); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.card == nil {
+				return fmt.Errorf("%s:%d: unknown card %q", path, e.line, e.name)
+			}
+		}
+	}
+	return write(os.Stdout, *name, entries)
+}
+
+func deckParser(path string) func(r io.Reader) ([]deckEntry, []deckProblem, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".cod":
+		return parseCockatriceDeck
+	case ".dck":
+		return parseXMageDeck
+	case ".dek":
+		return fromDecks(decks.ReadDek)
+	case ".csv":
+		return fromDecks(decks.ReadMoxfield)
+	}
+	return parseDeck
+}
+
+// fromDecks adapts a reader from the decks package to deckParser's.
+func fromDecks(read func(r io.Reader) ([]decks.Card, error)) func(r io.Reader) ([]deckEntry, []deckProblem, error) {
+	return func(r io.Reader) ([]deckEntry, []deckProblem, error) {
+		cards, err := read(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries := make([]deckEntry, len(cards))
+		for i, c := range cards {
+			entries[i] = fromCard(c)
+		}
+		return entries, nil, nil
+	}
+}
+
+func fromCard(c decks.Card) deckEntry {
+	return deckEntry{count: c.Count, name: c.Name, set: c.Set, number: c.Number, foil: c.Foil, section: c.Section}
+}
+
+// toDecks adapts a writer from the decks package to deckWriters'.
+func toDecks(write func(w io.Writer, cards []decks.Card) error) func(w io.Writer, name string, entries []deckEntry) error {
+	return func(w io.Writer, name string, entries []deckEntry) error {
+		cards := make([]decks.Card, len(entries))
+		for i, e := range entries {
+			cards[i] = decks.Card{Count: e.count, Name: e.name, Set: e.set, Number: e.number, Foil: e.foil, Section: e.section}
+		}
+		return write(w, cards)
+	}
+}
+
+func parseCockatriceDeck(r io.Reader) ([]deckEntry, []deckProblem, error) {
+	var deck cockatriceDeck
+	if err := xml.NewDecoder(r).Decode(&deck); err != nil {
+		return nil, nil, err
+	}
+
+	var entries []deckEntry
+	var problems []deckProblem
+	for _, zone := range deck.Zones {
+		section := mainSection
+		switch zone.Name {
+		case "main":
+		case "side":
+			section = sideboardSection
+		case "tokens":
+			continue
+		default:
+			problems = append(problems, deckProblem{Severity: "warning", Message: fmt.Sprintf("skipped unknown zone %q", zone.Name)})
+			continue
+		}
+		for _, c := range zone.Cards {
+			if c.Number < 1 {
+				problems = append(problems, deckProblem{Severity: "error", Card: c.Name, Message: fmt.Sprintf("invalid count %d for %s", c.Number, c.Name)})
+				continue
+			}
+			entries = append(entries, deckEntry{count: c.Number, name: c.Name, section: section})
+		}
+	}
+	return entries, problems, nil
+}
+
+// parseXMageDeck reads XMage's .dck lines, falling back to the plain-text
+// parser for lines without a [SET:NUMBER] printing. Forge also uses .dck, so
+// a file that starts with a Forge section header is read as Forge's format.
+func parseXMageDeck(r io.Reader) ([]deckEntry, []deckProblem, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isForgeDeck(data) {
+		return parseForgeDeck(data)
+	}
+
+	var entries []deckEntry
+	var problems []deckProblem
+	var plain []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "NAME:") || strings.HasPrefix(text, "LAYOUT ") {
+			plain = append(plain, "#")
+			continue
+		}
+
+		section := mainSection
+		if rest, ok := decks.CutPrefixFold(text, "SB:"); ok {
+			section = sideboardSection
+			text = strings.TrimSpace(rest)
+		}
+		m := xmageLinePattern.FindStringSubmatch(text)
+		if m == nil {
+			plain = append(plain, scanner.Text())
+			continue
+		}
+		plain = append(plain, "#")
+
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			problems = append(problems, deckProblem{Line: line, Severity: "error", Message: fmt.Sprintf("invalid count %q", m[1])})
+			continue
+		}
+		entries = append(entries, deckEntry{
+			line:    line,
+			count:   n,
+			name:    strings.TrimSpace(m[4]),
+			set:     strings.ToLower(m[2]),
+			number:  m[3],
+			section: section,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Consumed lines become comments, so the fallback keeps the right line
+	// numbers without treating them as a blank line before a sideboard.
+	rest, restProblems, err := parseDeck(strings.NewReader(strings.Join(plain, "\n")))
+	if err != nil {
+		return nil, nil, err
+	}
+	return append(entries, rest...), append(problems, restProblems...), nil
+}
+
+// isForgeDeck reports whether a .dck file starts with a Forge section
+// header such as "[metadata]" or "[Main]"; XMage lines never start with a
+// bracket.
+func isForgeDeck(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return forgeHeaderPattern.MatchString(line)
+		}
+	}
+	return false
+}
+
+// parseForgeDeck reads Forge's "4 Lightning Bolt|M10" lines, which can also
+// carry an art index after the set. Forge sections this tool has no place
+// for, such as [Planes], are skipped with a warning.
+func parseForgeDeck(data []byte) ([]deckEntry, []deckProblem, error) {
+	var entries []deckEntry
+	var problems []deckProblem
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if m := forgeHeaderPattern.FindStringSubmatch(text); m != nil {
+			header := strings.ToLower(m[1])
+			section = ""
+			for _, fs := range forgeSections {
+				if strings.ToLower(fs.header) == header {
+					section = fs.section
+					break
+				}
+			}
+			if section == "" && header != "metadata" {
+				problems = append(problems, deckProblem{Line: line, Severity: "warning", Message: fmt.Sprintf("skipped unknown section %q", m[1])})
+			}
+			continue
+		}
+		if section == "" {
+			continue
+		}
+
+		m := forgeLinePattern.FindStringSubmatch(text)
+		if m == nil {
+			problems = append(problems, deckProblem{Line: line, Severity: "warning", Message: fmt.Sprintf("skipped line without a count and card name: %q", text)})
+			continue
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			problems = append(problems, deckProblem{Line: line, Severity: "error", Message: fmt.Sprintf("invalid count %q", m[1])})
+			continue
+		}
+		entries = append(entries, deckEntry{
+			line:    line,
+			count:   n,
+			name:    strings.TrimSpace(m[2]),
+			set:     strings.ToLower(m[3]),
+			section: section,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return entries, problems, nil
+}
+
+// writeTextDeck keeps categories as "// Category" headers. Commanders and
+// companions are written without them, since a category header there would
+// start the main deck when the file is read back.
+func writeTextDeck(w io.Writer, name string, entries []deckEntry) error {
+	bw := bufio.NewWriter(w)
+	section := mainSection
+	for _, g := range deckGroups(entries) {
+		if g.section != section {
+			section = g.section
+			fmt.Fprintf(bw, "\n%s\n", strings.ToUpper(section[:1])+section[1:])
+		}
+		if g.category != "" && decks.CategorySection(section) == section {
+			fmt.Fprintf(bw, "// %s\n", g.category)
+		}
+		for _, e := range g.entries {
+			fmt.Fprintf(bw, "%d %s", e.count, e.name)
+			if e.set != "" {
+				fmt.Fprintf(bw, " (%s)", strings.ToUpper(e.set))
+				if e.number != "" {
+					fmt.Fprintf(bw, " %s", e.number)
+				}
+			}
+			if e.foil {
+				fmt.Fprint(bw, " *F*")
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeCockatriceDeck puts commanders in the main zone and companions in the
+// sideboard, since Cockatrice has no zones for them. Maybeboards are dropped.
+func writeCockatriceDeck(w io.Writer, name string, entries []deckEntry) error {
+	deck := cockatriceDeck{Version: "1", Name: name}
+	main := cockatriceZone{Name: "main"}
+	side := cockatriceZone{Name: "side"}
+	for _, e := range entries {
+		c := cockatriceCard{Number: e.count, Name: e.name}
+		switch e.section {
+		case mainSection, commanderSection:
+			main.Cards = append(main.Cards, c)
+		case sideboardSection, companionSection:
+			side.Cards = append(side.Cards, c)
+		}
+	}
+	deck.Zones = []cockatriceZone{main}
+	if len(side.Cards) > 0 {
+		deck.Zones = append(deck.Zones, side)
+	}
+
+	data, err := xml.MarshalIndent(deck, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to encode deck: %w", err)
+	}
+	_, err = io.WriteString(w, xml.Header+string(data)+"\n")
+	return err
+}
+
+// writeXMageDeck expects resolved entries so every line has a printing.
+// XMage keeps commanders and companions in the sideboard.
+func writeXMageDeck(w io.Writer, name string, entries []deckEntry) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "NAME:%s\n", name)
+	for _, e := range entries {
+		if e.section == maybeboardSection {
+			continue
+		}
+		set, number := e.set, e.number
+		if e.card != nil && (set == "" || number == "") {
+			set, number = e.card.Set, e.card.CollectorNumber
+		}
+		prefix := ""
+		if e.section != mainSection {
+			prefix = "SB: "
+		}
+		fmt.Fprintf(bw, "%s%d [%s:%s] %s\n", prefix, e.count, strings.ToUpper(set), number, e.name)
+	}
+	return bw.Flush()
+}
+
+var forgeSections = []struct{ section, header string }{
+	{commanderSection, "Commander"},
+	{mainSection, "Main"},
+	{sideboardSection, "Sideboard"},
+	{companionSection, "Sideboard"},
+}
+
+// writeForgeDeck writes Forge's sectioned .dck format with a set code on
+// each card so Forge picks the same printing.
+func writeForgeDeck(w io.Writer, name string, entries []deckEntry) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "[metadata]\nName=%s\n", name)
+	written := map[string]bool{}
+	for _, fs := range forgeSections {
+		for _, e := range entries {
+			if e.section != fs.section {
+				continue
+			}
+			if !written[fs.header] {
+				fmt.Fprintf(bw, "[%s]\n", fs.header)
+				written[fs.header] = true
+			}
+			set := e.set
+			if set == "" && e.card != nil {
+				set = e.card.Set
+			}
+			fmt.Fprintf(bw, "%d %s", e.count, e.name)
+			if set != "" {
+				fmt.Fprintf(bw, "|%s", strings.ToUpper(set))
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
+
+// writeUntapDeck writes an MTGO-style list for untap.in's paste import:
+// commanders first, then the main deck, a blank line, and the sideboard.
+func writeUntapDeck(w io.Writer, name string, entries []deckEntry) error {
+	bw := bufio.NewWriter(w)
+	for _, section := range []string{commanderSection, mainSection} {
+		for _, e := range entries {
+			if e.section == section {
+				fmt.Fprintf(bw, "%d %s\n", e.count, e.name)
+			}
+		}
+	}
+	first := true
+	for _, e := range entries {
+		if e.section != sideboardSection && e.section != companionSection {
+			continue
+		}
+		if first {
+			fmt.Fprintln(bw)
+			first = false
+		}
+		fmt.Fprintf(bw, "%d %s\n", e.count, e.name)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/cloudsmyth/tradingcardsearch/decks"
+)
+
+func runDeckGrep(args []string) error {
+	fs := flag.NewFlagSet("deck grep", flag.ExitOnError)
+	section := fs.String("section", "", "only search one section: main, sideboard, commander, companion, or maybeboard")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck grep [flags] <pattern> <file>...")
+		fmt.Fprintln(fs.Output(), "Prints deck lines whose card name, type line, or rules text matches a case-insensitive regular expression.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *section != "" && decks.Sections[*section] != *section {
+		fmt.Fprintf(os.Stderr, "Error: unknown section %q\n", *section)
+		os.Exit(2)
+	}
+	pattern, err := regexp.Compile("(?i)" + fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	found := false
+	for _, path := range fs.Args()[1:] {
+		entries, _, err := loadDeck(path)
+		if err != nil {
+			return err
+		}
+		if err := resolveDeck(entries); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if *section != "" && e.section != *section {
+				continue
+			}
+			text := e.name
+			if e.card != nil {
+				text += "\n" + e.card.TypeLine + "\n" + e.card.Text()
+			}
+			if pattern.MatchString(text) {
+				found = true
+				fmt.Printf("%s:%d: %d %s (%s)\n", path, e.line, e.count, e.name, e.section)
+			}
+		}
+	}
+	if !found {
+		os.Exit(1)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/sha1"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func runDeckHash(args []string) error {
+	fs := flag.NewFlagSet("deck hash", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck hash <file>")
+		fmt.Fprintln(fs.Output(), "Prints the Cockatrice deck hash, which webcam tournaments use to check that a played list matches the registered one.")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	entries, problems, err := loadDeck(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		p := problems[0]
+		return fmt.Errorf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	fmt.Println(cockatriceHash(entries))
+	return nil
+}
+
+// cockatriceHash follows Cockatrice's DeckList::updateDeckHash: one
+// lowercased name per copy, "SB:" before sideboard cards, sorted and joined
+// with ";", then the first 40 bits of the SHA-1 written in base 32. Names are
+// hashed as written, so double-faced cards must use their front face name as
+// Cockatrice does.
+func cockatriceHash(entries []deckEntry) string {
+	var names []string
+	for _, e := range entries {
+		prefix := ""
+		switch e.section {
+		case sideboardSection, companionSection:
+			prefix = "SB:"
+		case maybeboardSection:
+			continue
+		}
+		for i := 0; i < e.count; i++ {
+			names = append(names, prefix+strings.ToLower(e.name))
+		}
+	}
+	sort.Strings(names)
+
+	sum := sha1.Sum([]byte(strings.Join(names, ";")))
+	n := uint64(sum[0])<<32 | uint64(sum[1])<<24 | uint64(sum[2])<<16 | uint64(sum[3])<<8 | uint64(sum[4])
+	return fmt.Sprintf("%08s", strconv.FormatUint(n, 32))
+}
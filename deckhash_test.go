@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// The expected hashes were computed separately from Cockatrice's
+// DeckList::updateDeckHash, with Python's hashlib.
+func TestCockatriceHash(t *testing.T) {
+	tests := []struct {
+		name string
+		deck string
+		want string
+	}{
+		{
+			name: "main and sideboard",
+			deck: "4 Lightning Bolt\n20 Mountain\n4 Goblin Guide\n\nSideboard\n2 Smash to Smithereens\n",
+			want: "761ucglq",
+		},
+		{
+			// The same cards in another order and case hash the same, and
+			// the maybeboard is left out.
+			name: "order and case",
+			deck: "SB: 2 smash to smithereens\n4 GOBLIN GUIDE\n20 Mountain\n4 lightning bolt\n\nMaybeboard\n1 Skullcrack\n",
+			want: "761ucglq",
+		},
+		{
+			name: "companion in the sideboard",
+			deck: "4 Lightning Bolt\n20 Mountain\n4 Goblin Guide\nCompanion\n2 Smash to Smithereens\n",
+			want: "761ucglq",
+		},
+		{
+			// The hash's first base-32 digit is 0, which must be kept.
+			name: "zero padded",
+			deck: "4 Lightning Bolt\n47 Mountain\n",
+			want: "0dvsh8bm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, problems, err := parseDeck(strings.NewReader(tt.deck))
+			if err != nil || len(problems) > 0 {
+				t.Fatalf("parseDeck: %v %+v", err, problems)
+			}
+			if got := cockatriceHash(entries); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
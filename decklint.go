@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const maxCopies = 4
+
+// ownLimitPattern matches the copy limit that cards such as Seven Dwarves
+// and Nazgûl set for themselves.
+var ownLimitPattern = regexp.MustCompile(`A deck can have up to (\w+) cards named`)
+
+var numberWords = map[string]int{
+	"two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+func runDeckLint(args []string) error {
+	fs := flag.NewFlagSet("deck lint", flag.ExitOnError)
+	format := fs.String("format", "text", "diagnostic output: text (file:line: message) or json")
+	legal := fs.String("legal", "", "also check legality in this format, e.g. modern or commander")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck lint [flags] <file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || (*format != "text" && *format != "json") {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	entries, problems, err := loadDeck(path)
+	if err != nil {
+		return err
+	}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+	found, err := lintDeck(entries, strings.ToLower(*legal))
+	if err != nil {
+		return err
+	}
+	for _, p := range found {
+		p.File = path
+		problems = append(problems, p)
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+
+	if *format == "json" {
+		if problems == nil {
+			problems = []deckProblem{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(problems); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Printf("%s:%d: %s: %s\n", p.File, p.Line, p.Severity, p.Message)
+		}
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func lintDeck(entries []deckEntry, format string) ([]deckProblem, error) {
+	var problems []deckProblem
+	counts := map[string]int{}
+	first := map[string]deckEntry{}
+	var names []string
+
+	for _, e := range entries {
+		if e.card == nil {
+			problems = append(problems, deckProblem{Line: e.line, Severity: "error", Card: e.name, Message: fmt.Sprintf("unknown card %q", e.name)})
+			continue
+		}
+		if e.section == maybeboardSection {
+			continue
+		}
+
+		name := e.card.Name
+		if _, ok := first[name]; !ok {
+			first[name] = e
+			names = append(names, name)
+		}
+		counts[name] += e.count
+
+		if format == "" {
+			continue
+		}
+		status, ok := e.card.Legalities[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q", format)
+		}
+		switch status {
+		case "legal":
+		case "restricted":
+		case "banned":
+			problems = append(problems, deckProblem{Line: e.line, Severity: "error", Card: name, Message: fmt.Sprintf("%s is banned in %s", name, format)})
+		default:
+			problems = append(problems, deckProblem{Line: e.line, Severity: "error", Card: name, Message: fmt.Sprintf("%s is not legal in %s", name, format)})
+		}
+	}
+
+	for _, name := range names {
+		e := first[name]
+		limit := maxCopies
+		if rulesFor(format).singleton || (format != "" && e.card.Legalities[format] == "restricted") {
+			limit = 1
+		}
+		if n, ok := ownLimit(e); ok {
+			limit = n
+		}
+		if counts[name] > limit && !anyNumberAllowed(e) {
+			problems = append(problems, deckProblem{Line: e.line, Severity: "error", Card: name, Message: fmt.Sprintf("%d copies of %s; the limit is %d", counts[name], name, limit)})
+		}
+	}
+	return problems, nil
+}
+
+// anyNumberAllowed covers basic lands and cards such as Relentless Rats that
+// lift the copy limit themselves.
+func anyNumberAllowed(e deckEntry) bool {
+	return strings.HasPrefix(e.card.TypeLine, "Basic ") || strings.Contains(e.card.OracleText, "A deck can have any number of cards named")
+}
+
+// ownLimit returns the copy limit a card's text sets, which replaces the
+// format's, singleton formats included.
+func ownLimit(e deckEntry) (int, bool) {
+	m := ownLimitPattern.FindStringSubmatch(e.card.OracleText)
+	if m == nil {
+		return 0, false
+	}
+	n, ok := numberWords[strings.ToLower(m[1])]
+	return n, ok
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var deckExtensions = map[string]bool{".txt": true, ".dek": true, ".csv": true, ".cod": true, ".dck": true}
+
+var decksCommands = map[string]func(args []string) error{
+	"price": runDecksPrice,
+	"check": runDecksCheck,
+}
+
+func runDecks(args []string) error {
+	if len(args) == 0 || decksCommands[args[0]] == nil {
+		var names []string
+		for name := range decksCommands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Usage: decks <%s> [flags] <file or directory>...\n", strings.Join(names, "|"))
+		os.Exit(2)
+	}
+	return decksCommands[args[0]](args[1:])
+}
+
+// deckFiles expands directories to the deck files directly inside them.
+func deckFiles(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", arg, err)
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", arg, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() && deckExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+				paths = append(paths, filepath.Join(arg, e.Name()))
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no deck files found")
+	}
+	return paths, nil
+}
+
+func runDecksPrice(args []string) error {
+	fs := flag.NewFlagSet("decks price", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: decks price <file or directory>...")
+		fmt.Fprintln(fs.Output(), "Prints the USD price of each deck, leaving out maybeboards, and the total.")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	paths, err := deckFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Deck\tCards\tUSD\tUnpriced")
+	var total float64
+	for _, path := range paths {
+		entries, _, err := loadDeck(path)
+		if err != nil {
+			return err
+		}
+		if err := resolveDeck(entries); err != nil {
+			return err
+		}
+
+		cards := 0
+		for _, e := range entries {
+			if e.section != maybeboardSection {
+				cards += e.count
+			}
+		}
+		usd, unpriced := deckPrice(entries)
+		total += usd
+		fmt.Fprintf(tw, "%s\t%d\t$%.2f\t%d\n", path, cards, usd, unpriced)
+	}
+	fmt.Fprintf(tw, "Total\t\t$%.2f\t\n", total)
+	return tw.Flush()
+}
+
+func deckEntryPrice(e deckEntry) string {
+	if e.card == nil {
+		return ""
+	}
+	if e.foil && e.card.Prices.USDFoil != "" {
+		return e.card.Prices.USDFoil
+	}
+	return e.card.Prices.USD
+}
+
+func runDecksCheck(args []string) error {
+	fs := flag.NewFlagSet("decks check", flag.ExitOnError)
+	format := fs.String("format", "", "also check legality in this format, e.g. modern or commander")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: decks check [flags] <file or directory>...")
+		fmt.Fprintln(fs.Output(), "Runs deck lint on each deck and prints a summary; run deck lint on a deck for the details.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	paths, err := deckFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Deck\tCards\tErrors\tWarnings\tStatus")
+	failed := 0
+	for _, path := range paths {
+		entries, problems, err := loadDeck(path)
+		if err != nil {
+			return err
+		}
+		if err := resolveDeck(entries); err != nil {
+			return err
+		}
+		found, err := lintDeck(entries, strings.ToLower(*format))
+		if err != nil {
+			return err
+		}
+		problems = append(problems, found...)
+
+		cards := 0
+		for _, e := range entries {
+			if e.section != maybeboardSection {
+				cards += e.count
+			}
+		}
+		errs, warnings := 0, 0
+		for _, p := range problems {
+			if p.Severity == "error" {
+				errs++
+			} else {
+				warnings++
+			}
+		}
+		status := "ok"
+		if len(problems) > 0 {
+			status = "problems"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\n", path, cards, errs, warnings, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d decks have problems\n", failed, len(paths))
+		os.Exit(1)
+	}
+	return nil
+}
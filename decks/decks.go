@@ -0,0 +1,124 @@
+// Package decks reads and writes decklists in the formats MTG Arena, Magic
+// Online, and Moxfield import and export, and the plain-text lists of the
+// common deck sites. Readers return an error naming the line for input they
+// cannot read, except ReadText, which skips such lines and reports them.
+package decks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sections a card can be in.
+const (
+	Main       = "main"
+	Sideboard  = "sideboard"
+	Commander  = "commander"
+	Companion  = "companion"
+	Maybeboard = "maybeboard"
+)
+
+// Card is a line of a decklist: some copies of a card, optionally of one
+// printing. Set is a lowercase Scryfall set code.
+type Card struct {
+	Count   int
+	Name    string
+	Set     string
+	Number  string
+	Foil    bool
+	Section string
+}
+
+// ReadArena reads an MTG Arena export with ReadText. Sections start with a
+// Deck, Sideboard, Commander, or Companion header; in older exports without
+// headers a blank line starts the sideboard.
+func ReadArena(r io.Reader) ([]Card, error) {
+	return readCards(r)
+}
+
+// readCards reads a text decklist, failing on the first line ReadText could
+// not read.
+func readCards(r io.Reader) ([]Card, error) {
+	entries, problems, err := ReadText(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("line %d: %s", problems[0].Line, problems[0].Message)
+	}
+	cards := make([]Card, len(entries))
+	for i, e := range entries {
+		cards[i] = e.Card
+	}
+	return cards, nil
+}
+
+var arenaSections = []struct{ section, header string }{
+	{Commander, "Commander"},
+	{Companion, "Companion"},
+	{Main, "Deck"},
+	{Sideboard, "Sideboard"},
+}
+
+// WriteArena writes an MTG Arena import, with the printing on each card that
+// has one. Maybeboard cards are left out.
+func WriteArena(w io.Writer, cards []Card) error {
+	bw := bufio.NewWriter(w)
+	first := true
+	for _, s := range arenaSections {
+		header := false
+		for _, c := range cards {
+			if c.Section != s.section {
+				continue
+			}
+			if !header {
+				if !first {
+					fmt.Fprintln(bw)
+				}
+				fmt.Fprintln(bw, s.header)
+				header, first = true, false
+			}
+			fmt.Fprintf(bw, "%d %s", c.Count, c.Name)
+			if c.Set != "" {
+				fmt.Fprintf(bw, " (%s)", strings.ToUpper(c.Set))
+				if c.Number != "" {
+					fmt.Fprintf(bw, " %s", c.Number)
+				}
+			}
+			fmt.Fprintln(bw)
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadMTGO reads a Magic Online .txt export with ReadText: the main deck, a
+// blank line or "Sideboard" header, and the sideboard.
+func ReadMTGO(r io.Reader) ([]Card, error) {
+	return readCards(r)
+}
+
+// WriteMTGO writes a Magic Online .txt import. MTGO keeps commanders and
+// companions in the sideboard, so they are written there, and maybeboard
+// cards are left out.
+func WriteMTGO(w io.Writer, cards []Card) error {
+	bw := bufio.NewWriter(w)
+	for _, c := range cards {
+		if c.Section == Main {
+			fmt.Fprintf(bw, "%d %s\n", c.Count, c.Name)
+		}
+	}
+	first := true
+	for _, c := range cards {
+		if c.Section != Sideboard && c.Section != Commander && c.Section != Companion {
+			continue
+		}
+		if first {
+			fmt.Fprintln(bw)
+			first = false
+		}
+		fmt.Fprintf(bw, "%d %s\n", c.Count, c.Name)
+	}
+	return bw.Flush()
+}
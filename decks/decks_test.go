@@ -0,0 +1,149 @@
+package decks
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var roundTrips = []struct {
+	name  string
+	read  func(r io.Reader) ([]Card, error)
+	write func(w io.Writer, cards []Card) error
+	cards []Card
+}{
+	{
+		name:  "arena",
+		read:  ReadArena,
+		write: WriteArena,
+		cards: []Card{
+			{Count: 1, Name: "Krenko, Mob Boss", Set: "rna", Number: "5", Section: Commander},
+			{Count: 1, Name: "Lurrus of the Dream-Den", Set: "iko", Number: "226", Section: Companion},
+			{Count: 4, Name: "Lightning Bolt", Set: "m10", Number: "146", Section: Main},
+			{Count: 1, Name: "Fire // Ice", Set: "mh2", Number: "290", Section: Main},
+			{Count: 20, Name: "Mountain", Section: Main},
+			{Count: 2, Name: "Smash to Smithereens", Set: "ori", Section: Sideboard},
+		},
+	},
+	{
+		name:  "mtgo",
+		read:  ReadMTGO,
+		write: WriteMTGO,
+		cards: []Card{
+			{Count: 4, Name: "Lightning Bolt", Section: Main},
+			{Count: 1, Name: "Fire // Ice", Section: Main},
+			{Count: 2, Name: "Smash to Smithereens", Section: Sideboard},
+		},
+	},
+	{
+		name:  "moxfield",
+		read:  ReadMoxfield,
+		write: WriteMoxfield,
+		cards: []Card{
+			{Count: 4, Name: "Lightning Bolt", Set: "m10", Number: "146", Section: Main},
+			{Count: 1, Name: "Fire // Ice, the \"Split\" Card", Set: "mh2", Number: "290", Foil: true, Section: Main},
+			{Count: 1, Name: "Black Lotus", Section: Main},
+		},
+	},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range roundTrips {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf, tt.cards); err != nil {
+				t.Fatal(err)
+			}
+			written := buf.String()
+			got, err := tt.read(&buf)
+			if err != nil {
+				t.Fatalf("reading back:\n%s\n%v", written, err)
+			}
+			if !reflect.DeepEqual(got, tt.cards) {
+				t.Errorf("round trip changed the deck\n--- written\n%s\n--- got\n%+v\n--- want\n%+v", written, got, tt.cards)
+			}
+		})
+	}
+}
+
+func TestReadArenaExport(t *testing.T) {
+	export := `About
+Name Mono Red
+
+Deck
+4 Lightning Bolt (M10) 146
+20 Mountain (ANA) 60
+
+Sideboard
+2 Smash to Smithereens (ORI) 163
+`
+	got, err := ReadArena(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Card{
+		{Count: 4, Name: "Lightning Bolt", Set: "m10", Number: "146", Section: Main},
+		{Count: 20, Name: "Mountain", Set: "ana", Number: "60", Section: Main},
+		{Count: 2, Name: "Smash to Smithereens", Set: "ori", Number: "163", Section: Sideboard},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadArenaWithoutHeaders(t *testing.T) {
+	got, err := ReadArena(strings.NewReader("4 Lightning Bolt\n\n2 Smash to Smithereens\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Section != Main || got[1].Section != Sideboard {
+		t.Errorf("got %+v, want the card after the blank line in the sideboard", got)
+	}
+}
+
+func TestMTGOPutsCommandersInSideboard(t *testing.T) {
+	var buf bytes.Buffer
+	cards := []Card{
+		{Count: 1, Name: "Krenko, Mob Boss", Section: Commander},
+		{Count: 99, Name: "Mountain", Section: Main},
+		{Count: 1, Name: "Goblin Guide", Section: Maybeboard},
+	}
+	if err := WriteMTGO(&buf, cards); err != nil {
+		t.Fatal(err)
+	}
+	if want := "99 Mountain\n\n1 Krenko, Mob Boss\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadDek(t *testing.T) {
+	dek := `<?xml version="1.0" encoding="utf-8"?>
+<Deck xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <NetDeckID>0</NetDeckID>
+  <PreconstructedDeckID>0</PreconstructedDeckID>
+  <Cards CatID="31977" Quantity="4" Sideboard="false" Name="Lightning Bolt" Annotation="0" />
+  <Cards CatID="56734" Quantity="2" Sideboard="true" Name="Smash to Smithereens" Annotation="0" />
+</Deck>`
+	got, err := ReadDek(strings.NewReader(dek))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Card{
+		{Count: 4, Name: "Lightning Bolt", Section: Main},
+		{Count: 2, Name: "Smash to Smithereens", Section: Sideboard},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestReadErrorsNameTheLine(t *testing.T) {
+	if _, err := ReadArena(strings.NewReader("4 Lightning Bolt\n4 1234\n")); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("ReadArena error = %v, want one for line 2", err)
+	}
+	if _, err := ReadMoxfield(strings.NewReader("Count,Name\nfour,Lightning Bolt\n")); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("ReadMoxfield error = %v, want one for line 2", err)
+	}
+}
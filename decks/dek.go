@@ -0,0 +1,39 @@
+package decks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type dekFile struct {
+	XMLName xml.Name  `xml:"Deck"`
+	Cards   []dekCard `xml:"Cards"`
+}
+
+type dekCard struct {
+	Quantity  int    `xml:"Quantity,attr"`
+	Sideboard bool   `xml:"Sideboard,attr"`
+	Name      string `xml:"Name,attr"`
+}
+
+// ReadDek reads a Magic Online .dek file, the XML format MTGO saves decks
+// in.
+func ReadDek(r io.Reader) ([]Card, error) {
+	var deck dekFile
+	if err := xml.NewDecoder(r).Decode(&deck); err != nil {
+		return nil, err
+	}
+	cards := make([]Card, 0, len(deck.Cards))
+	for _, c := range deck.Cards {
+		if c.Quantity < 1 {
+			return nil, fmt.Errorf("invalid quantity %d for %s", c.Quantity, c.Name)
+		}
+		section := Main
+		if c.Sideboard {
+			section = Sideboard
+		}
+		cards = append(cards, Card{Count: c.Quantity, Name: c.Name, Section: section})
+	}
+	return cards, nil
+}
@@ -0,0 +1,83 @@
+package decks
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// moxfieldHeader is the header of Moxfield's collection CSV, which its
+// collection and deck importers both accept.
+var moxfieldHeader = []string{"Count", "Tradelist Count", "Name", "Edition", "Condition", "Language", "Foil", "Tags", "Last Modified", "Collector Number", "Alter", "Proxy", "Purchase Price"}
+
+// ReadMoxfield reads a Moxfield CSV export. Columns are found by name, so
+// only Count and Name are required. The CSV has no sections, so every card
+// is in the main deck.
+func ReadMoxfield(r io.Reader) ([]Card, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	column := map[string]int{}
+	for i, name := range header {
+		column[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"count", "name"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("missing %q column", name)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := column[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var cards []Card
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(field(row, "count"))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("line %d: invalid count %q", line, field(row, "count"))
+		}
+		cards = append(cards, Card{
+			Count:   n,
+			Name:    field(row, "name"),
+			Set:     strings.ToLower(field(row, "edition")),
+			Number:  field(row, "collector number"),
+			Foil:    field(row, "foil") != "",
+			Section: Main,
+		})
+	}
+	return cards, nil
+}
+
+// WriteMoxfield writes Moxfield's collection CSV, with near mint English
+// copies. Maybeboard cards are left out.
+func WriteMoxfield(w io.Writer, cards []Card) error {
+	cw := csv.NewWriter(w)
+	cw.Write(moxfieldHeader)
+	for _, c := range cards {
+		if c.Section == Maybeboard {
+			continue
+		}
+		foil := ""
+		if c.Foil {
+			foil = "foil"
+		}
+		cw.Write([]string{strconv.Itoa(c.Count), "0", c.Name, c.Set, "Near Mint", "English", foil, "", "", c.Number, "False", "False", ""})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,234 @@
+package decks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Sections maps the lowercased section headers that text decklists use to
+// sections.
+var Sections = map[string]string{
+	"deck":        Main,
+	"main":        Main,
+	"main deck":   Main,
+	"mainboard":   Main,
+	"sideboard":   Sideboard,
+	"side":        Sideboard,
+	"commander":   Commander,
+	"commanders":  Commander,
+	"companion":   Companion,
+	"maybeboard":  Maybeboard,
+	"maybe":       Maybeboard,
+	"considering": Maybeboard,
+}
+
+var (
+	// countPattern matches the count in "4 Lightning Bolt" and
+	// "4x Lightning Bolt". Lines without one are a single copy.
+	countPattern = regexp.MustCompile(`^(\d+)\s*[xX]?\s+(.*)$`)
+	// headerCountPattern matches the "(15)" that some sites put after
+	// section and category headers.
+	headerCountPattern = regexp.MustCompile(`\s*\(\d+\)$`)
+
+	// Suffixes that can follow a card name, in any order.
+	printingPattern = regexp.MustCompile(`\s+\(([A-Za-z0-9]{2,6})\)(?:\s+([0-9][^\s()]*|[^\s()]*[0-9]))?$`)
+	bracketPattern  = regexp.MustCompile(`\s+\[([^\[\]]+)\]$`)
+	tagPattern      = regexp.MustCompile(`\s+#!?(\S+)$`)
+	markerPattern   = regexp.MustCompile(`\s+(?:\*[A-Za-z]+\*|\^[^^]*\^|<[^<>]*>)$`)
+	// setTagPattern is a bracketed set tag, "[M10]" or XMage's "[M10:146]";
+	// other bracketed text is a category, as in Archidekt exports.
+	setTagPattern = regexp.MustCompile(`^([A-Z0-9]{2,6})(?::(\S+))?$`)
+)
+
+// Entry is a card read from a text decklist, with the line it is on and the
+// category it is under, such as "Ramp", from a "// Ramp" header or a tag on
+// the line.
+type Entry struct {
+	Card
+	Line     int
+	Category string
+}
+
+// Problem is a line of a text decklist that could not be read.
+type Problem struct {
+	Line    int
+	Message string
+}
+
+// ReadText reads a plain-text decklist, accepting the formats of the common
+// deck sites in any mix, Arena and MTGO exports among them. Sections start
+// with a header line such as "Sideboard" or "// Sideboard", or with an "SB:"
+// prefix. "Creatures (20)" lines name a category, and so do other "//"
+// lines directly above a card; the rest are comments. An Arena "About"
+// header and the lines under it are skipped. Without section headers or
+// categories after the first card, a blank line after the main deck starts
+// the sideboard, as in MTGO exports. Lines that cannot be read are returned
+// as problems and skipped.
+func ReadText(r io.Reader) ([]Entry, []Problem, error) {
+	var entries []Entry
+	var problems []Problem
+	section := Main
+	category := ""
+	sawHeader := false
+	about := false
+	sideboardAt := -1
+	// pending is a "//" comment that becomes a category if a card follows it.
+	pending := ""
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			if len(entries) > 0 && sideboardAt < 0 {
+				sideboardAt = len(entries)
+			}
+			pending = ""
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(text, "//"); ok {
+			header := headerName(rest)
+			if s, ok := Sections[strings.ToLower(header)]; ok {
+				section, category, about, pending = s, "", false, ""
+				sawHeader = true
+			} else if header != "" && !countPattern.MatchString(header) {
+				pending = header
+			}
+			continue
+		}
+		if s, ok := Sections[strings.ToLower(headerName(text))]; ok {
+			section, category, about, pending = s, "", false, ""
+			sawHeader = true
+			continue
+		}
+		// "About" starts an Arena deck's name and description, which come
+		// before the cards.
+		if strings.EqualFold(text, "about") {
+			about, sawHeader, pending = true, true, ""
+			continue
+		}
+		if about {
+			continue
+		}
+		if !countPattern.MatchString(text) && headerCountPattern.MatchString(text) {
+			section, category, pending = CategorySection(section), headerName(text), ""
+			sawHeader = true
+			continue
+		}
+		if pending != "" {
+			// A category before the first card may just be a title, so
+			// only a later one rules out a blank-line sideboard.
+			if len(entries) > 0 {
+				sawHeader = true
+			}
+			section, category, pending = CategorySection(section), pending, ""
+		}
+
+		e := Entry{Card: Card{Count: 1, Section: section}, Line: line, Category: category}
+		if rest, ok := CutPrefixFold(text, "SB:"); ok {
+			e.Section = Sideboard
+			sawHeader = true
+			text = strings.TrimSpace(rest)
+		}
+		if err := parseLine(text, &e); err != nil {
+			problems = append(problems, Problem{Line: line, Message: err.Error()})
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if !sawHeader && sideboardAt >= 0 {
+		for i := sideboardAt; i < len(entries); i++ {
+			entries[i].Section = Sideboard
+		}
+	}
+	return entries, problems, nil
+}
+
+// ParseLine reads one card line of a text decklist, such as
+// "4 Lightning Bolt (M10) 146 *F*". Without a count it is a single copy, in
+// the main deck unless a bracketed tag names a section.
+func ParseLine(text string) (Entry, error) {
+	e := Entry{Card: Card{Count: 1, Section: Main}}
+	err := parseLine(strings.TrimSpace(text), &e)
+	return e, err
+}
+
+func parseLine(text string, e *Entry) error {
+	if m := countPattern.FindStringSubmatch(text); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid count %q", m[1])
+		}
+		e.Count, text = n, m[2]
+	}
+
+	for {
+		if m := markerPattern.FindStringIndex(text); m != nil {
+			e.Foil = e.Foil || strings.TrimSpace(text[m[0]:]) == "*F*"
+			text = text[:m[0]]
+		} else if m := tagPattern.FindStringSubmatchIndex(text); m != nil {
+			e.Category = text[m[2]:m[3]]
+			text = text[:m[0]]
+		} else if m := bracketPattern.FindStringSubmatchIndex(text); m != nil {
+			tag := text[m[2]:m[3]]
+			if t := setTagPattern.FindStringSubmatch(tag); t != nil && e.Set == "" {
+				e.Set, e.Number = strings.ToLower(t[1]), t[2]
+			} else if tag, _, _ = strings.Cut(tag, "{"); Sections[strings.ToLower(tag)] != "" {
+				e.Section = Sections[strings.ToLower(tag)]
+			} else {
+				e.Category, _, _ = strings.Cut(tag, ",")
+			}
+			text = text[:m[0]]
+		} else if m := printingPattern.FindStringSubmatchIndex(text); m != nil && e.Set == "" {
+			e.Set = strings.ToLower(text[m[2]:m[3]])
+			if m[4] >= 0 {
+				e.Number = text[m[4]:m[5]]
+			}
+			text = text[:m[0]]
+		} else {
+			break
+		}
+	}
+
+	e.Name = strings.TrimSpace(text)
+	if !strings.ContainsFunc(e.Name, unicode.IsLetter) {
+		return fmt.Errorf("no card name in %q", text)
+	}
+	return nil
+}
+
+// headerName trims the punctuation and card count around a header.
+func headerName(text string) string {
+	text = strings.TrimSpace(headerCountPattern.ReplaceAllString(strings.TrimSpace(text), ""))
+	return strings.TrimSpace(strings.TrimSuffix(text, ":"))
+}
+
+// CategorySection returns the section for cards under a category header.
+// Categories group main deck cards, so they end a commander or companion
+// section, which hold a card or two.
+func CategorySection(section string) string {
+	if section == Commander || section == Companion {
+		return Main
+	}
+	return section
+}
+
+// CutPrefixFold is strings.CutPrefix ignoring case, for prefixes such as
+// "SB:".
+func CutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
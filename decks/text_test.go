@@ -0,0 +1,133 @@
+package decks
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summary writes an entry as "count name [set number] section/category".
+func summary(e Entry) string {
+	s := fmt.Sprintf("%d %s", e.Count, e.Name)
+	if e.Set != "" {
+		s += fmt.Sprintf(" (%s %s)", e.Set, e.Number)
+	}
+	if e.Foil {
+		s += " foil"
+	}
+	s += " " + e.Section
+	if e.Category != "" {
+		s += "/" + e.Category
+	}
+	return s
+}
+
+var readTextTests = []struct {
+	name string
+	text string
+	want []string
+}{
+	{
+		name: "arena",
+		text: "About\nName Mono Red\n\nCommander\n1 Krenko, Mob Boss (RNA) 5\n\nDeck\n4 Lightning Bolt (M10) 146\n\nSideboard\n2 Smash to Smithereens (ORI) 163\n",
+		want: []string{
+			"1 Krenko, Mob Boss (rna 5) commander",
+			"4 Lightning Bolt (m10 146) main",
+			"2 Smash to Smithereens (ori 163) sideboard",
+		},
+	},
+	{
+		name: "mtgo blank line",
+		text: "4 Lightning Bolt\n1 Fire // Ice\n\n2 Smash to Smithereens\n",
+		want: []string{
+			"4 Lightning Bolt main",
+			"1 Fire // Ice main",
+			"2 Smash to Smithereens sideboard",
+		},
+	},
+	{
+		name: "count-less",
+		text: "Lightning Bolt\n4x Goblin Guide\nSol Ring *F*\nSB: Duress\n",
+		want: []string{
+			"1 Lightning Bolt main",
+			"4 Goblin Guide main",
+			"1 Sol Ring foil main",
+			"1 Duress sideboard",
+		},
+	},
+	{
+		name: "comment before a blank-line sideboard",
+		text: "// Deck by Someone\n\n4 Lightning Bolt\n\n2 Smash to Smithereens\n",
+		want: []string{
+			"4 Lightning Bolt main",
+			"2 Smash to Smithereens sideboard",
+		},
+	},
+	{
+		name: "title directly above the cards",
+		text: "// Mono Red\n4 Lightning Bolt\n\n2 Smash to Smithereens\n",
+		want: []string{
+			"4 Lightning Bolt main/Mono Red",
+			"2 Smash to Smithereens sideboard/Mono Red",
+		},
+	},
+	{
+		name: "categories",
+		text: "Commander\n1 Krenko, Mob Boss\n\n// Ramp\n1 Sol Ring\n\n// Creatures (2)\n2 Goblin Guide\nInstants (4)\n4 Lightning Bolt\n3 Skullcrack #Burn\n// Sideboard\n2 Duress [Hate]\n",
+		want: []string{
+			"1 Krenko, Mob Boss commander",
+			"1 Sol Ring main/Ramp",
+			"2 Goblin Guide main/Creatures",
+			"4 Lightning Bolt main/Instants",
+			"3 Skullcrack main/Burn",
+			"2 Duress sideboard/Hate",
+		},
+	},
+	{
+		name: "bracketed printings and sections",
+		text: "1 Lightning Bolt [M10:146]\n1 Goblin Guide [Maybeboard]\n",
+		want: []string{
+			"1 Lightning Bolt (m10 146) main",
+			"1 Goblin Guide maybeboard",
+		},
+	},
+}
+
+func TestReadText(t *testing.T) {
+	for _, tt := range readTextTests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, problems, err := ReadText(strings.NewReader(tt.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) > 0 {
+				t.Errorf("unexpected problems: %+v", problems)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, summary(e))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestReadTextProblems(t *testing.T) {
+	entries, problems, err := ReadText(strings.NewReader("4 Lightning Bolt\n0 Goblin Guide\n# a comment\n4 1234\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries, want 1", len(entries))
+	}
+	want := []Problem{
+		{Line: 2, Message: `invalid count "0"`},
+		{Line: 4, Message: `no card name in "1234"`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("got %+v, want %+v", problems, want)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// deckTypeOrder picks the type a card is counted under, so an artifact
+// creature is a creature and a Dryad Arbor is a land.
+var deckTypeOrder = []string{"Land", "Creature", "Planeswalker", "Battle", "Instant", "Sorcery", "Artifact", "Enchantment"}
+
+var manaSymbolPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func runDeckStats(args []string) error {
+	fs := flag.NewFlagSet("deck stats", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck stats <file>")
+		fmt.Fprintln(fs.Output(), "Prints the main deck's mana curve, colors, card types, average mana value, and price.")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+
+	entries, problems, err := loadDeck(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", p.File, p.Line, p.Severity, p.Message)
+	}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.card == nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: unknown card %q left out of the statistics\n", path, e.line, e.name)
+		}
+	}
+	fmt.Print(renderDeckStats(path, entries))
+	return nil
+}
+
+// renderDeckStats describes the cards that are played: the main deck,
+// commanders, and companion.
+func renderDeckStats(name string, entries []deckEntry) string {
+	var b strings.Builder
+	var played []deckEntry
+	total := 0
+	for _, e := range entries {
+		if e.card != nil && inMainDeck(e) {
+			played = append(played, e)
+			total += e.count
+		}
+	}
+	b.WriteString(cardTitleStyle.Render(fmt.Sprintf("Statistics for %s: %d cards", name, total)))
+	b.WriteString("\n\n")
+
+	rows, spells := manaCurve(played)
+	writeCountSection(&b, "Mana curve (nonland)", rows, spells)
+
+	colors := map[string]int{}
+	pips := map[string]int{}
+	types := map[string]int{}
+	var mv float64
+	for _, e := range played {
+		face := e.card.Faces()[0]
+		for _, t := range deckTypeOrder {
+			if strings.Contains(face.TypeLine, t) {
+				types[t] += e.count
+				break
+			}
+		}
+		if strings.Contains(face.TypeLine, "Land") {
+			continue
+		}
+		mv += e.card.CMC * float64(e.count)
+
+		switch c := cardColors(e.card); len(c) {
+		case 0:
+			colors["Colorless"] += e.count
+		case 1:
+			colors[c[0]] += e.count
+		default:
+			colors["Multicolor"] += e.count
+		}
+		cost := e.card.ManaCost
+		if cost == "" {
+			cost = face.ManaCost
+		}
+		for _, m := range manaSymbolPattern.FindAllStringSubmatch(cost, -1) {
+			for _, c := range statsColorOrder[:5] {
+				if strings.Contains(m[1], c) {
+					pips[c] += e.count
+				}
+			}
+		}
+	}
+
+	rows = nil
+	for _, c := range statsColorOrder {
+		if n := colors[c]; n > 0 {
+			label := c
+			if full, ok := statsColorNames[c]; ok {
+				label = full
+			}
+			rows = append(rows, countRow{label, n})
+		}
+	}
+	writeCountSection(&b, "Colors (nonland)", rows, spells)
+
+	rows = nil
+	symbols := 0
+	for _, c := range statsColorOrder[:5] {
+		if n := pips[c]; n > 0 {
+			rows = append(rows, countRow{statsColorNames[c], n})
+			symbols += n
+		}
+	}
+	if symbols > 0 {
+		writeCountSection(&b, "Colored mana symbols", rows, symbols)
+	}
+
+	rows = nil
+	for _, t := range deckTypeOrder {
+		if n := types[t]; n > 0 {
+			rows = append(rows, countRow{t, n})
+		}
+	}
+	writeCountSection(&b, "Card types", rows, total)
+
+	creatures, lands := types["Creature"], types["Land"]
+	fmt.Fprintf(&b, "%d creatures • %d other spells • %d lands\n", creatures, total-creatures-lands, lands)
+	if spells > 0 {
+		fmt.Fprintf(&b, "Average mana value (nonland): %.2f\n", mv/float64(spells))
+	}
+	usd, unpriced := deckPrice(entries)
+	fmt.Fprintf(&b, "Total price: $%.2f", usd)
+	if unpriced > 0 {
+		fmt.Fprintf(&b, " (%d cards without a price)", unpriced)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// deckRules are the construction rules of a format beyond card legality.
+type deckRules struct {
+	size      int
+	exact     bool
+	singleton bool
+	commander bool
+	sideboard int
+}
+
+var constructedRules = deckRules{size: 60, sideboard: 15}
+
+var formatRules = map[string]deckRules{
+	"commander":       {size: 100, exact: true, singleton: true, commander: true},
+	"duel":            {size: 100, exact: true, singleton: true, commander: true},
+	"paupercommander": {size: 100, exact: true, singleton: true, commander: true},
+	"predh":           {size: 100, exact: true, singleton: true, commander: true},
+	"brawl":           {size: 100, exact: true, singleton: true, commander: true},
+	"standardbrawl":   {size: 60, exact: true, singleton: true, commander: true},
+	"oathbreaker":     {size: 60, exact: true, singleton: true, commander: true},
+	"gladiator":       {size: 100, exact: true, singleton: true},
+}
+
+func rulesFor(format string) deckRules {
+	if rules, ok := formatRules[format]; ok {
+		return rules
+	}
+	return constructedRules
+}
+
+func runDeckValidate(args []string) error {
+	fs := flag.NewFlagSet("deck validate", flag.ExitOnError)
+	format := fs.String("format", "", "format to validate against, e.g. modern or commander")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: deck validate -format <format> <file>")
+		fmt.Fprintln(fs.Output(), "Checks legality, copy limits, deck size, and for Commander formats the singleton and color identity rules.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *format == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	path := fs.Arg(0)
+	name := strings.ToLower(*format)
+
+	entries, problems, err := loadDeck(path)
+	if err != nil {
+		return err
+	}
+	if err := resolveDeck(entries); err != nil {
+		return err
+	}
+	found, err := lintDeck(entries, name)
+	if err != nil {
+		return err
+	}
+	problems = append(problems, found...)
+	problems = append(problems, validateDeck(entries, rulesFor(name))...)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+
+	for _, p := range problems {
+		if p.Line == 0 {
+			fmt.Printf("%s: %s: %s\n", path, p.Severity, p.Message)
+		} else {
+			fmt.Printf("%s:%d: %s: %s\n", path, p.Line, p.Severity, p.Message)
+		}
+	}
+	for _, p := range problems {
+		if p.Severity == "error" {
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("%s is legal in %s\n", path, name)
+	return nil
+}
+
+// validateDeck checks the deck as a whole: its size, its sideboard, and for
+// Commander formats that it has a commander whose color identity covers
+// every other card. Legality and copy limits are left to lintDeck.
+func validateDeck(entries []deckEntry, rules deckRules) []deckProblem {
+	var problems []deckProblem
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[e.section] += e.count
+	}
+
+	size := counts[mainSection] + counts[commanderSection]
+	switch {
+	case rules.exact && size != rules.size:
+		problems = append(problems, deckProblem{Severity: "error", Message: fmt.Sprintf("the deck has %d cards; it must have exactly %d", size, rules.size)})
+	case size < rules.size:
+		problems = append(problems, deckProblem{Severity: "error", Message: fmt.Sprintf("the deck has %d cards; it needs at least %d", size, rules.size)})
+	}
+	// A companion starts the game outside the deck, so it is one of the
+	// sideboard's cards.
+	sideboard := counts[sideboardSection] + counts[companionSection]
+	if rules.sideboard > 0 && sideboard > rules.sideboard {
+		what := "the sideboard has"
+		if counts[companionSection] > 0 {
+			what = "the sideboard and companion have"
+		}
+		problems = append(problems, deckProblem{Severity: "error", Message: fmt.Sprintf("%s %d cards; the limit is %d", what, sideboard, rules.sideboard)})
+	}
+
+	if !rules.commander {
+		return problems
+	}
+	if counts[commanderSection] == 0 {
+		return append(problems, deckProblem{Severity: "error", Message: "the deck has no commander"})
+	}
+	identity := map[string]bool{}
+	for _, e := range entries {
+		if e.section == commanderSection && e.card != nil {
+			for _, c := range e.card.ColorIdentity {
+				identity[c] = true
+			}
+		}
+	}
+	allowed := identityString(identity)
+	for _, e := range entries {
+		if e.card == nil || (e.section != mainSection && e.section != companionSection) {
+			continue
+		}
+		for _, c := range e.card.ColorIdentity {
+			if !identity[c] {
+				problems = append(problems, deckProblem{Line: e.line, Severity: "error", Card: e.card.Name, Message: fmt.Sprintf("%s (%s) is outside the commander's color identity (%s)", e.card.Name, strings.Join(e.card.ColorIdentity, ""), allowed)})
+				break
+			}
+		}
+	}
+	return problems
+}
+
+func identityString(identity map[string]bool) string {
+	var b strings.Builder
+	for _, c := range []string{"W", "U", "B", "R", "G"} {
+		if identity[c] {
+			b.WriteString(c)
+		}
+	}
+	if b.Len() == 0 {
+		return "colorless"
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+func TestValidateDeckCompanionInSideboard(t *testing.T) {
+	entries := []deckEntry{
+		{count: 60, name: "Mountain", section: mainSection},
+		{count: 15, name: "Smash to Smithereens", section: sideboardSection},
+	}
+	if problems := validateDeck(entries, constructedRules); len(problems) > 0 {
+		t.Fatalf("a full sideboard without a companion: %+v", problems)
+	}
+	entries = append(entries, deckEntry{count: 1, name: "Lurrus of the Dream-Den", section: companionSection})
+	problems := validateDeck(entries, constructedRules)
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "16 cards") {
+		t.Errorf("got %+v, want the companion to be the sideboard's 16th card", problems)
+	}
+}
+
+func TestLintDeckOwnCopyLimits(t *testing.T) {
+	dwarves := &scryfall.Card{
+		Name:       "Seven Dwarves",
+		TypeLine:   "Creature — Dwarf",
+		OracleText: "Seven Dwarves gets +1/+1 for each other creature named Seven Dwarves you control.\nA deck can have up to seven cards named Seven Dwarves.",
+		Legalities: map[string]string{"modern": "legal", "commander": "legal"},
+	}
+	rats := &scryfall.Card{
+		Name:       "Relentless Rats",
+		TypeLine:   "Creature — Rat",
+		OracleText: "A deck can have any number of cards named Relentless Rats.",
+		Legalities: map[string]string{"modern": "legal", "commander": "legal"},
+	}
+	tests := []struct {
+		format string
+		card   *scryfall.Card
+		count  int
+		want   int
+	}{
+		{format: "modern", card: dwarves, count: 7},
+		{format: "modern", card: dwarves, count: 8, want: 1},
+		{format: "commander", card: dwarves, count: 7},
+		{format: "modern", card: rats, count: 20},
+		{format: "commander", card: rats, count: 20},
+	}
+	for _, tt := range tests {
+		entries := []deckEntry{{line: 1, count: tt.count, name: tt.card.Name, section: mainSection, card: tt.card}}
+		problems, err := lintDeck(entries, tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(problems) != tt.want {
+			t.Errorf("%d %s in %s: got %+v, want %d problems", tt.count, tt.card.Name, tt.format, problems, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+	"github.com/cloudsmyth/tradingcardsearch/store"
+)
+
+// staleBulkAge is how old the bulk data can get before doctor suggests a
+// sync. Scryfall refreshes it daily.
+const staleBulkAge = 7 * 24 * time.Hour
+
+// doctorIssue is a problem doctor found. A nil action means another issue's
+// fix takes care of it.
+type doctorIssue struct {
+	problem string
+	fix     string
+	action  func() error
+}
+
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	fix := fs.Bool("fix", false, "repair the problems found")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: doctor [-fix]")
+		fmt.Fprintln(fs.Output(), "Checks the offline data for damage left by interrupted syncs and old releases.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	bulk, err := bulkPath()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(bulk)
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("No offline data in %s; run the sync command for -offline searches.\n", dir)
+		return nil
+	}
+
+	issues, err := diagnose(bulk)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Println("No problems found.")
+		return nil
+	}
+	for _, issue := range issues {
+		fmt.Printf("%s\n  fix: %s\n", issue.problem, issue.fix)
+	}
+	if !*fix {
+		fmt.Println("\nRun doctor -fix to repair these.")
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	for _, issue := range issues {
+		if issue.action == nil {
+			continue
+		}
+		if err := issue.action(); err != nil {
+			return err
+		}
+	}
+	fmt.Println("Done.")
+	return nil
+}
+
+func diagnose(bulk string) ([]doctorIssue, error) {
+	var issues []doctorIssue
+	dir := filepath.Dir(bulk)
+	remove := func(path string) func() error {
+		return func() error {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			fmt.Printf("Removed %s\n", path)
+			return nil
+		}
+	}
+
+	lock := bulk + ".lock"
+	if info, err := os.Stat(lock); err == nil {
+		owner, _ := os.ReadFile(lock)
+		age := time.Since(info.ModTime())
+		if age <= staleLockAge {
+			return nil, fmt.Errorf("a sync is running (%s, started %s ago); run doctor when it finishes", strings.TrimSpace(string(owner)), age.Round(time.Second))
+		}
+		issues = append(issues, doctorIssue{
+			problem: fmt.Sprintf("%s was left by a sync that did not finish (%s)", lock, strings.TrimSpace(string(owner))),
+			fix:     "remove the lock",
+			action:  remove(lock),
+		})
+	}
+
+	// Syncs write under these names and rename when done, so any left over
+	// are from a sync that was killed.
+	for _, pattern := range []string{bulkKind + "-*.json", "cards-*.db", "cards-*.db-journal"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			size := int64(0)
+			if info, err := os.Stat(path); err == nil {
+				size = info.Size()
+			}
+			issues = append(issues, doctorIssue{
+				problem: fmt.Sprintf("%s is a leftover temporary file (%.0f MB)", path, float64(size)/1e6),
+				fix:     "remove it",
+				action:  remove(path),
+			})
+		}
+	}
+
+	resync := func() error { return runSync(nil) }
+	info, err := os.Stat(bulk)
+	if errors.Is(err, os.ErrNotExist) {
+		return append(issues, doctorIssue{problem: fmt.Sprintf("%s is missing", bulk), fix: "run sync", action: resync}), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", bulk, err)
+	}
+	ids := map[string]bool{}
+	err = scanBulk(bulk, func(card scryfall.Card) error {
+		ids[card.ID] = true
+		return nil
+	})
+	if err != nil {
+		return append(issues, doctorIssue{problem: err.Error(), fix: "download it again with sync", action: resync}), nil
+	}
+	synced := false
+	if age := time.Since(info.ModTime()); age > staleBulkAge {
+		issues = append(issues, doctorIssue{
+			problem: fmt.Sprintf("%s is %d days old", bulk, int(age.Hours()/24)),
+			fix:     "run sync",
+			action:  resync,
+		})
+		synced = true
+	}
+
+	if problem := checkIndex(info.ModTime(), len(ids)); problem != "" {
+		issue := doctorIssue{problem: problem, fix: "rebuild the index", action: func() error { return buildIndex(bulk) }}
+		if synced {
+			issue.fix, issue.action = "rebuilt by sync", nil
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// checkIndex describes what is wrong with the search index, if anything,
+// given the bulk file it should have been built from.
+func checkIndex(bulkTime time.Time, cards int) string {
+	path, err := indexPath()
+	if err != nil {
+		return err.Error()
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Sprintf("%s is missing, so offline searches scan the whole bulk file", path)
+	}
+	if err != nil {
+		return fmt.Sprintf("failed to read %s: %v", path, err)
+	}
+
+	db, err := store.OpenReadOnly(path)
+	if errors.Is(err, store.ErrOutdated) {
+		return fmt.Sprintf("%s was built by an older release", path)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	defer db.Close()
+	indexed, err := db.Check()
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s: %v", path, err)
+	case indexed != cards:
+		return fmt.Sprintf("%s has %d cards but the bulk data has %d", path, indexed, cards)
+	case info.ModTime().Before(bulkTime):
+		return fmt.Sprintf("%s is older than the bulk data", path)
+	}
+	return ""
+}
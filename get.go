@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+type entry struct {
+	card     *scryfall.Card
+	quantity int
+	foil     bool
+}
+
+type entryBatch struct {
+	lastSet string
+	entries []entry
+	out     *os.File
+	start   int64
+}
+
+func runGet(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	batch := fs.Bool("batch", false, "read set/number lines from stdin until EOF or \"done\"")
+	setCode := fs.String("set", "", "starting set code for batch mode")
+	outPath := fs.String("out", "", "append entered cards to this CSV file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: get [flags] <set> <collector number>")
+		fmt.Fprintln(fs.Output(), "       get -batch [-set code] [-out cards.csv]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	b := &entryBatch{lastSet: strings.ToLower(*setCode)}
+
+	if *outPath != "" {
+		f, err := os.OpenFile(*outPath, os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", *outPath, err)
+		}
+		defer f.Close()
+		if b.start, err = f.Seek(0, io.SeekEnd); err != nil {
+			return fmt.Errorf("failed to open %s: %w", *outPath, err)
+		}
+		b.out = f
+	}
+
+	if !*batch {
+		if fs.NArg() != 2 {
+			fs.Usage()
+			os.Exit(2)
+		}
+		e, err := b.add(fs.Args())
+		if err != nil {
+			return err
+		}
+		if err := b.save(); err != nil {
+			return err
+		}
+		fmt.Println(formatEntry(e))
+		return nil
+	}
+
+	fmt.Println("Enter \"<set> <number>\" or just \"<number>\" to reuse the last set.")
+	fmt.Println("Append \"xN\" for quantity and \"foil\" for foils; \"undo\" removes the last card, \"done\" finishes.")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Printf("[%s] > ", strings.ToUpper(b.lastSet))
+		if !scanner.Scan() {
+			fmt.Println()
+			break
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "done" {
+			break
+		}
+		if fields[0] == "undo" {
+			if len(b.entries) == 0 {
+				fmt.Println("Nothing to undo")
+				continue
+			}
+			last := b.entries[len(b.entries)-1]
+			b.entries = b.entries[:len(b.entries)-1]
+			if err := b.save(); err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s — %s\n", last.card.Name, b.totals())
+			continue
+		}
+
+		e, err := b.add(fields)
+		if err != nil {
+			fmt.Println(errorStyle.Render(fmt.Sprintf("Error: %v", err)))
+			continue
+		}
+		if err := b.save(); err != nil {
+			return err
+		}
+		fmt.Printf("%s — %s\n", formatEntry(e), b.totals())
+	}
+
+	fmt.Printf("Entered %s\n", b.totals())
+	return scanner.Err()
+}
+
+func (b *entryBatch) add(fields []string) (entry, error) {
+	e := entry{quantity: 1}
+
+	var positional []string
+	for _, f := range fields {
+		lower := strings.ToLower(f)
+		switch {
+		case lower == "foil" || lower == "f":
+			e.foil = true
+		case isQuantity(lower):
+			e.quantity, _ = strconv.Atoi(lower[1:])
+		default:
+			positional = append(positional, f)
+		}
+	}
+
+	set, number := b.lastSet, ""
+	switch len(positional) {
+	case 1:
+		number = positional[0]
+	case 2:
+		set, number = strings.ToLower(positional[0]), positional[1]
+	default:
+		return e, fmt.Errorf("expected \"<set> <number>\" or \"<number>\"")
+	}
+	if set == "" {
+		return e, fmt.Errorf("no set given yet")
+	}
+
+	card, err := api.GetCard(set, number)
+	if err != nil {
+		return e, err
+	}
+	e.card = card
+	b.lastSet = set
+	b.entries = append(b.entries, e)
+	return e, nil
+}
+
+// save rewrites this session's rows after whatever the CSV file held
+// before, so undo also removes the row from disk.
+func (b *entryBatch) save() error {
+	if b.out == nil {
+		return nil
+	}
+	if err := b.out.Truncate(b.start); err != nil {
+		return fmt.Errorf("failed to write entries: %w", err)
+	}
+	if _, err := b.out.Seek(b.start, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to write entries: %w", err)
+	}
+
+	w := csv.NewWriter(b.out)
+	for _, e := range b.entries {
+		w.Write([]string{e.card.Set, e.card.CollectorNumber, e.card.Name, strconv.Itoa(e.quantity), strconv.FormatBool(e.foil), entryPrice(e)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write entries: %w", err)
+	}
+	return nil
+}
+
+func isQuantity(field string) bool {
+	if len(field) < 2 || field[0] != 'x' {
+		return false
+	}
+	n, err := strconv.Atoi(field[1:])
+	return err == nil && n > 0
+}
+
+func (b *entryBatch) totals() string {
+	count, value := 0, 0.0
+	for _, e := range b.entries {
+		count += e.quantity
+		if p, err := strconv.ParseFloat(entryPrice(e), 64); err == nil {
+			value += p * float64(e.quantity)
+		}
+	}
+	return fmt.Sprintf("%d cards, $%.2f", count, value)
+}
+
+func entryPrice(e entry) string {
+	if e.foil {
+		return e.card.Prices.USDFoil
+	}
+	return e.card.Prices.USD
+}
+
+func formatEntry(e entry) string {
+	var b strings.Builder
+
+	if e.quantity > 1 {
+		fmt.Fprintf(&b, "%dx ", e.quantity)
+	}
+	fmt.Fprintf(&b, "%s (%s #%s)", e.card.Name, strings.ToUpper(e.card.Set), e.card.CollectorNumber)
+	if e.foil {
+		b.WriteString(" foil")
+	}
+	if price := entryPrice(e); price != "" {
+		fmt.Fprintf(&b, " $%s", price)
+	} else {
+		b.WriteString(" no price")
+	}
+	return b.String()
+}
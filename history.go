@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const maxHistory = 1000
+
+func historyPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".mtg-go-search_history"), nil
+}
+
+// loadHistory returns the most recent maxHistory searches, oldest first.
+func loadHistory() []string {
+	path, err := historyPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	return lines
+}
+
+// saveHistory appends one search to the history file, under the lock so
+// searches from two sessions do not interleave. History is a convenience, so
+// failures to write it, a held lock among them, are ignored.
+func saveHistory(line string) {
+	path, err := historyPath()
+	if err != nil {
+		return
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	f.WriteString(line + "\n")
+}
+
+// remember records a submitted search and resets history browsing.
+func (m *model) remember(line string) {
+	if line != "" && (len(m.recent) == 0 || m.recent[len(m.recent)-1] != line) {
+		m.recent = append(m.recent, line)
+		saveHistory(line)
+	}
+	m.recentPos = len(m.recent)
+}
+
+// browseHistory moves through earlier searches like a shell's up and down
+// arrows, keeping the unfinished input to come back to.
+func (m *model) browseHistory(step int) {
+	pos := m.recentPos + step
+	if pos < 0 || pos > len(m.recent) {
+		return
+	}
+	if m.recentPos == len(m.recent) {
+		m.draft = m.textInput.Value()
+	}
+	m.recentPos = pos
+	if pos == len(m.recent) {
+		m.textInput.SetValue(m.draft)
+	} else {
+		m.textInput.SetValue(m.recent[pos])
+	}
+	m.textInput.CursorEnd()
+}
+
+// findHistory returns the newest search before index from that contains
+// the reverse search text, or -1.
+func (m *model) findHistory(from int) int {
+	for i := min(from, len(m.recent)) - 1; i >= 0; i-- {
+		if strings.Contains(m.recent[i], m.reverse) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+const ircMaxMessage = 400
+
+type ircMessage struct {
+	prefix  string
+	command string
+	params  []string
+}
+
+type ircSession struct {
+	name       string
+	addr       string
+	useTLS     bool
+	pass       string
+	nick       string
+	channels   []string
+	maxMessage int
+	cooldown   *botCooldown
+
+	conn net.Conn
+}
+
+func runIRC(args []string) error {
+	fs := flag.NewFlagSet("irc", flag.ExitOnError)
+	server := fs.String("server", "", "IRC server address (host:port)")
+	useTLS := fs.Bool("tls", true, "connect using TLS")
+	nick := fs.String("nick", "mtgsearch", "bot nickname")
+	pass := fs.String("pass", os.Getenv("IRC_PASSWORD"), "server password (defaults to $IRC_PASSWORD)")
+	channels := fs.String("channels", "", "comma-separated list of channels to join")
+	cooldown := fs.Duration("cooldown", 5*time.Second, "minimum time between replies in a channel")
+	fs.Parse(args)
+
+	if *server == "" {
+		return fmt.Errorf("no server: pass -server host:port")
+	}
+
+	session := &ircSession{
+		name:       "irc",
+		addr:       *server,
+		useTLS:     *useTLS,
+		pass:       *pass,
+		nick:       *nick,
+		channels:   splitChannels(*channels),
+		maxMessage: ircMaxMessage,
+		cooldown:   newBotCooldown(*cooldown),
+	}
+	return session.run()
+}
+
+func (s *ircSession) run() error {
+	var err error
+	if s.useTLS {
+		s.conn, err = tls.Dial("tcp", s.addr, nil)
+	} else {
+		s.conn, err = net.Dial("tcp", s.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.addr, err)
+	}
+	defer s.conn.Close()
+
+	if s.pass != "" {
+		s.send("PASS " + s.pass)
+	}
+	s.send("NICK " + s.nick)
+	s.send(fmt.Sprintf("USER %s 0 * :MTG card search", s.nick))
+
+	scanner := bufio.NewScanner(s.conn)
+	for scanner.Scan() {
+		msg := parseIRCLine(scanner.Text())
+		switch msg.command {
+		case "PING":
+			s.send("PONG :" + strings.Join(msg.params, " "))
+		case "001":
+			if len(s.channels) > 0 {
+				s.send("JOIN " + strings.Join(s.channels, ","))
+				log.Printf("%s bot joined %s", s.name, strings.Join(s.channels, ", "))
+			}
+		case "433":
+			return fmt.Errorf("nickname %s is already in use", s.nick)
+		case "NOTICE":
+			if len(msg.params) > 1 && strings.Contains(msg.params[1], "authentication failed") {
+				return fmt.Errorf("%s login failed: %s", s.name, msg.params[1])
+			}
+		case "PRIVMSG":
+			if len(msg.params) < 2 {
+				continue
+			}
+			s.handleMessage(msg.prefix, msg.params[0], msg.params[1])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("lost connection to %s: %w", s.addr, err)
+	}
+	return fmt.Errorf("%s closed the connection", s.addr)
+}
+
+func (s *ircSession) handleMessage(prefix, target, text string) {
+	replyTo := target
+	if !strings.HasPrefix(target, "#") && !strings.HasPrefix(target, "&") {
+		replyTo, _, _ = strings.Cut(prefix, "!")
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(text), "!") && !cardRefPattern.MatchString(text) {
+		return
+	}
+	// Other bots' commands such as !lurk get no reply, so they must not use
+	// up the cooldown.
+	replies := botReplies(text, s.maxMessage)
+	if len(replies) == 0 || !s.cooldown.allow(replyTo) {
+		return
+	}
+
+	for _, reply := range replies {
+		s.send(fmt.Sprintf("PRIVMSG %s :%s", replyTo, reply))
+	}
+}
+
+func (s *ircSession) send(line string) {
+	if _, err := fmt.Fprintf(s.conn, "%s\r\n", line); err != nil {
+		log.Printf("%s: send: %v", s.name, err)
+	}
+}
+
+func splitChannels(list string) []string {
+	var channels []string
+	for _, ch := range strings.Split(list, ",") {
+		ch = strings.TrimSpace(ch)
+		if ch == "" {
+			continue
+		}
+		if !strings.HasPrefix(ch, "#") && !strings.HasPrefix(ch, "&") {
+			ch = "#" + ch
+		}
+		channels = append(channels, ch)
+	}
+	return channels
+}
+
+func parseIRCLine(line string) ircMessage {
+	var msg ircMessage
+
+	if strings.HasPrefix(line, "@") {
+		if i := strings.Index(line, " "); i >= 0 {
+			line = line[i+1:]
+		}
+	}
+	if strings.HasPrefix(line, ":") {
+		i := strings.Index(line, " ")
+		if i < 0 {
+			return msg
+		}
+		msg.prefix, line = line[1:i], line[i+1:]
+	}
+
+	trailing := ""
+	hasTrailing := false
+	if i := strings.Index(line, " :"); i >= 0 {
+		trailing, line, hasTrailing = line[i+2:], line[:i], true
+	} else if strings.HasPrefix(line, ":") {
+		trailing, line, hasTrailing = line[1:], "", true
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) > 0 {
+		msg.command, msg.params = fields[0], fields[1:]
+	}
+	if hasTrailing {
+		msg.params = append(msg.params, trailing)
+	}
+	return msg
+}
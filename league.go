@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	matchWinPoints  = 3
+	matchDrawPoints = 1
+	minOpponentRate = 1.0 / 3
+)
+
+// leagueVersion is written to the league file so a later release can tell
+// which layout it is reading.
+const leagueVersion = 1
+
+type league struct {
+	Version int            `json:"version"`
+	Players []leaguePlayer `json:"players"`
+	Matches []leagueMatch  `json:"matches"`
+}
+
+type leaguePlayer struct {
+	Name string `json:"name"`
+	Deck string `json:"deck,omitempty"`
+}
+
+type leagueMatch struct {
+	Player1  string    `json:"player1"`
+	Player2  string    `json:"player2"`
+	Wins1    int       `json:"wins1"`
+	Wins2    int       `json:"wins2"`
+	Draws    int       `json:"draws,omitempty"`
+	PlayedAt time.Time `json:"played_at"`
+}
+
+type standing struct {
+	player      leaguePlayer
+	points      int
+	matchWins   int
+	matchLosses int
+	matchDraws  int
+	gameWins    int
+	gameLosses  int
+	gameDraws   int
+	opponents   []string
+	omw         float64
+}
+
+func runLeague(args []string) error {
+	fs := flag.NewFlagSet("league", flag.ExitOnError)
+	path := fs.String("file", "", "league data file (defaults to league.json in the config directory)")
+	asCSV := fs.Bool("csv", false, "print tables as CSV")
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, "Usage: league [flags] <command>")
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "Commands:")
+		fmt.Fprintln(out, "  add <player> [deck]              register a player, or change their deck")
+		fmt.Fprintln(out, "  record <p1> <p2> <wins>-<losses>[-<draws>]   record a match from p1's side")
+		fmt.Fprintln(out, "  standings                        standings table")
+		fmt.Fprintln(out, "  h2h <player>                     head-to-head records for one player")
+		fmt.Fprintln(out, "  matches                          every recorded match")
+		fmt.Fprintln(out, "")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return fmt.Errorf("failed to find config directory: %w", err)
+		}
+		*path = filepath.Join(dir, "mtg-go-search", "league.json")
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	// Changes read, modify, and write the file, so they hold a lock for the
+	// whole time in case another process is recording a match too.
+	if slices.Contains([]string{"add", "record"}, rest[0]) {
+		if err := os.MkdirAll(filepath.Dir(*path), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(*path), err)
+		}
+		unlock, err := lockFile(*path + ".lock")
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	l, err := loadLeague(*path)
+	if err != nil {
+		return err
+	}
+
+	switch rest[0] {
+	case "add":
+		if len(rest) < 2 || len(rest) > 3 {
+			return fmt.Errorf("usage: league add <player> [deck]")
+		}
+		deck := ""
+		if len(rest) == 3 {
+			deck = rest[2]
+		}
+		l.addPlayer(rest[1], deck)
+		return l.save(*path)
+
+	case "record":
+		if len(rest) != 4 {
+			return fmt.Errorf("usage: league record <p1> <p2> <wins>-<losses>[-<draws>]")
+		}
+		if err := l.record(rest[1], rest[2], rest[3]); err != nil {
+			return err
+		}
+		return l.save(*path)
+
+	case "standings":
+		return writeTable(*asCSV, []string{"Rank", "Player", "Deck", "Points", "Matches", "Games", "OMW%"}, l.standingsRows())
+
+	case "h2h":
+		if len(rest) != 2 {
+			return fmt.Errorf("usage: league h2h <player>")
+		}
+		rows, err := l.headToHead(rest[1])
+		if err != nil {
+			return err
+		}
+		return writeTable(*asCSV, []string{"Opponent", "Matches", "Games"}, rows)
+
+	case "matches":
+		var rows [][]string
+		for _, m := range l.Matches {
+			rows = append(rows, []string{m.PlayedAt.Format(dateLayout), m.Player1, m.Player2, formatRecord(m.Wins1, m.Wins2, m.Draws)})
+		}
+		return writeTable(*asCSV, []string{"Date", "Player", "Opponent", "Result"}, rows)
+	}
+
+	return fmt.Errorf("unknown league command %q", rest[0])
+}
+
+func loadLeague(path string) (*league, error) {
+	l := &league{Version: leagueVersion}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	// Files from before the version field read as version 0, which has the
+	// same layout as version 1.
+	if l.Version > leagueVersion {
+		return nil, fmt.Errorf("%s was written by a newer release (version %d)", path, l.Version)
+	}
+	if l.Version < 0 {
+		return nil, fmt.Errorf("%s has an unknown version %d", path, l.Version)
+	}
+	l.Version = leagueVersion
+	return l, nil
+}
+
+func (l *league) save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode league: %w", err)
+	}
+	return replaceFile(path, data)
+}
+
+func (l *league) player(name string) *leaguePlayer {
+	for i := range l.Players {
+		if strings.EqualFold(l.Players[i].Name, name) {
+			return &l.Players[i]
+		}
+	}
+	return nil
+}
+
+func (l *league) addPlayer(name, deck string) {
+	if p := l.player(name); p != nil {
+		p.Deck = deck
+		fmt.Printf("Updated %s\n", p.Name)
+		return
+	}
+	l.Players = append(l.Players, leaguePlayer{Name: name, Deck: deck})
+	fmt.Printf("Registered %s\n", name)
+}
+
+func (l *league) record(name1, name2, result string) error {
+	p1, p2 := l.player(name1), l.player(name2)
+	if p1 == nil {
+		return fmt.Errorf("unknown player %q; register them with league add", name1)
+	}
+	if p2 == nil {
+		return fmt.Errorf("unknown player %q; register them with league add", name2)
+	}
+	if p1 == p2 {
+		return fmt.Errorf("a player cannot play themselves")
+	}
+
+	parts := strings.Split(result, "-")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("invalid result %q: expected wins-losses or wins-losses-draws", result)
+	}
+	games := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid result %q: expected wins-losses or wins-losses-draws", result)
+		}
+		games[i] = n
+	}
+	if games[0]+games[1]+games[2] == 0 {
+		return fmt.Errorf("invalid result %q: no games were played", result)
+	}
+
+	m := leagueMatch{
+		Player1:  p1.Name,
+		Player2:  p2.Name,
+		Wins1:    games[0],
+		Wins2:    games[1],
+		Draws:    games[2],
+		PlayedAt: time.Now(),
+	}
+	l.Matches = append(l.Matches, m)
+	fmt.Printf("Recorded %s %s %s\n", m.Player1, formatRecord(m.Wins1, m.Wins2, m.Draws), m.Player2)
+	return nil
+}
+
+func (l *league) standings() []*standing {
+	byName := map[string]*standing{}
+	var table []*standing
+	for _, p := range l.Players {
+		s := &standing{player: p}
+		byName[strings.ToLower(p.Name)] = s
+		table = append(table, s)
+	}
+
+	for _, m := range l.Matches {
+		s1, s2 := byName[strings.ToLower(m.Player1)], byName[strings.ToLower(m.Player2)]
+		if s1 == nil || s2 == nil {
+			continue
+		}
+		s1.addMatch(m.Player2, m.Wins1, m.Wins2, m.Draws)
+		s2.addMatch(m.Player1, m.Wins2, m.Wins1, m.Draws)
+	}
+
+	for _, s := range table {
+		total := 0.0
+		for _, opp := range s.opponents {
+			total += byName[strings.ToLower(opp)].matchWinRate()
+		}
+		if len(s.opponents) > 0 {
+			s.omw = total / float64(len(s.opponents))
+		}
+	}
+
+	sort.SliceStable(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.points != b.points {
+			return a.points > b.points
+		}
+		if a.omw != b.omw {
+			return a.omw > b.omw
+		}
+		if ga, gb := a.gameWinRate(), b.gameWinRate(); ga != gb {
+			return ga > gb
+		}
+		return strings.ToLower(a.player.Name) < strings.ToLower(b.player.Name)
+	})
+	return table
+}
+
+func (s *standing) addMatch(opponent string, wins, losses, draws int) {
+	s.gameWins += wins
+	s.gameLosses += losses
+	s.gameDraws += draws
+	s.opponents = append(s.opponents, opponent)
+	switch {
+	case wins > losses:
+		s.matchWins++
+		s.points += matchWinPoints
+	case wins < losses:
+		s.matchLosses++
+	default:
+		s.matchDraws++
+		s.points += matchDrawPoints
+	}
+}
+
+func (s *standing) matchWinRate() float64 {
+	played := s.matchWins + s.matchLosses + s.matchDraws
+	if played == 0 {
+		return minOpponentRate
+	}
+	rate := float64(s.points) / float64(played*matchWinPoints)
+	if rate < minOpponentRate {
+		return minOpponentRate
+	}
+	return rate
+}
+
+// gameWinRate scores games like matches, three points for a win and one for
+// a draw, out of three points a game.
+func (s *standing) gameWinRate() float64 {
+	played := s.gameWins + s.gameLosses + s.gameDraws
+	if played == 0 {
+		return 0
+	}
+	points := s.gameWins*matchWinPoints + s.gameDraws*matchDrawPoints
+	return float64(points) / float64(played*matchWinPoints)
+}
+
+func (l *league) standingsRows() [][]string {
+	var rows [][]string
+	for i, s := range l.standings() {
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			s.player.Name,
+			s.player.Deck,
+			strconv.Itoa(s.points),
+			formatRecord(s.matchWins, s.matchLosses, s.matchDraws),
+			formatRecord(s.gameWins, s.gameLosses, s.gameDraws),
+			fmt.Sprintf("%.1f", s.omw*100),
+		})
+	}
+	return rows
+}
+
+func (l *league) headToHead(name string) ([][]string, error) {
+	p := l.player(name)
+	if p == nil {
+		return nil, fmt.Errorf("unknown player %q", name)
+	}
+
+	records := map[string]*standing{}
+	var order []string
+	for _, m := range l.Matches {
+		var opp string
+		var wins, losses int
+		switch {
+		case strings.EqualFold(m.Player1, p.Name):
+			opp, wins, losses = m.Player2, m.Wins1, m.Wins2
+		case strings.EqualFold(m.Player2, p.Name):
+			opp, wins, losses = m.Player1, m.Wins2, m.Wins1
+		default:
+			continue
+		}
+		key := strings.ToLower(opp)
+		if records[key] == nil {
+			records[key] = &standing{player: leaguePlayer{Name: opp}}
+			order = append(order, key)
+		}
+		records[key].addMatch(opp, wins, losses, m.Draws)
+	}
+
+	sort.Strings(order)
+	var rows [][]string
+	for _, key := range order {
+		r := records[key]
+		rows = append(rows, []string{
+			r.player.Name,
+			formatRecord(r.matchWins, r.matchLosses, r.matchDraws),
+			formatRecord(r.gameWins, r.gameLosses, r.gameDraws),
+		})
+	}
+	return rows, nil
+}
+
+func formatRecord(wins, losses, draws int) string {
+	if draws > 0 {
+		return fmt.Sprintf("%d-%d-%d", wins, losses, draws)
+	}
+	return fmt.Sprintf("%d-%d", wins, losses)
+}
+
+func writeTable(asCSV bool, header []string, rows [][]string) error {
+	if asCSV {
+		w := csv.NewWriter(os.Stdout)
+		w.Write(header)
+		w.WriteAll(rows)
+		return w.Error()
+	}
+
+	if len(rows) == 0 {
+		fmt.Println("Nothing recorded yet")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLeagueRecord(t *testing.T) {
+	tests := []struct {
+		result  string
+		want    [3]int
+		wantErr bool
+	}{
+		{result: "2-1", want: [3]int{2, 1, 0}},
+		{result: "1-1-1", want: [3]int{1, 1, 1}},
+		{result: "0-2", want: [3]int{0, 2, 0}},
+		{result: "0-0-1", want: [3]int{0, 0, 1}},
+		{result: "0-0", wantErr: true},
+		{result: "0-0-0", wantErr: true},
+		{result: "2", wantErr: true},
+		{result: "2-1-0-0", wantErr: true},
+		{result: "2--1", wantErr: true},
+		{result: "two-one", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			l := &league{Players: []leaguePlayer{{Name: "Alice"}, {Name: "Bob"}}}
+			err := l.record("alice", "Bob", tt.result)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", l.Matches)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := l.Matches[0]
+			if got := [3]int{m.Wins1, m.Wins2, m.Draws}; got != tt.want || m.Player1 != "Alice" {
+				t.Errorf("got %s %v, want Alice %v", m.Player1, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeagueRecordPlayers(t *testing.T) {
+	l := &league{Players: []leaguePlayer{{Name: "Alice"}}}
+	if err := l.record("Alice", "Carol", "2-0"); err == nil {
+		t.Error("recorded a match against an unregistered player")
+	}
+	if err := l.record("Alice", "ALICE", "2-0"); err == nil {
+		t.Error("recorded a match against the same player")
+	}
+}
+
+func TestLeagueStandings(t *testing.T) {
+	l := &league{
+		Players: []leaguePlayer{{Name: "Alice"}, {Name: "Bob"}, {Name: "Carol"}, {Name: "Dave"}},
+		Matches: []leagueMatch{
+			{Player1: "Alice", Player2: "Bob", Wins1: 2, Wins2: 0, Draws: 1},
+			{Player1: "Alice", Player2: "Carol", Wins1: 2, Wins2: 1},
+			{Player1: "Carol", Player2: "Bob", Wins1: 1, Wins2: 1, Draws: 1},
+			{Player1: "Dave", Player2: "Bob", Wins1: 2, Wins2: 0},
+		},
+	}
+	byName := map[string]*standing{}
+	var order []string
+	for _, s := range l.standings() {
+		byName[s.player.Name] = s
+		order = append(order, s.player.Name)
+	}
+
+	// Bob and Carol drew for a point each. Carol's one other opponent is
+	// Alice, but Bob's include Dave too, so Bob's opponents did better.
+	want := []string{"Alice", "Dave", "Bob", "Carol"}
+	for i := range want {
+		if i >= len(order) || order[i] != want[i] {
+			t.Fatalf("order %v, want %v", order, want)
+		}
+	}
+
+	alice := byName["Alice"]
+	if alice.points != 6 || alice.matchWins != 2 || alice.gameDraws != 1 {
+		t.Errorf("Alice: %d points, %d match wins, %d game draws; want 6, 2, 1", alice.points, alice.matchWins, alice.gameDraws)
+	}
+	// 4 game wins and a draw in 6 games: 13 of 18 game points.
+	if got := alice.gameWinRate(); math.Abs(got-13.0/18) > 1e-9 {
+		t.Errorf("Alice game win rate %v, want 13/18", got)
+	}
+
+	// Bob has a draw in three matches, 1/9 of the points, which is raised to
+	// the 1/3 floor when it counts towards his opponents.
+	bob := byName["Bob"]
+	if bob.points != 1 || bob.matchDraws != 1 || bob.matchLosses != 2 {
+		t.Errorf("Bob: %d points, %d draws, %d losses; want 1, 1, 2", bob.points, bob.matchDraws, bob.matchLosses)
+	}
+	if got := bob.matchWinRate(); got != minOpponentRate {
+		t.Errorf("Bob match win rate %v, want the %v floor", got, minOpponentRate)
+	}
+	if got := byName["Dave"].omw; got != minOpponentRate {
+		t.Errorf("Dave OMW %v, want %v", got, minOpponentRate)
+	}
+	// Alice, Carol floored at 1/3, and Dave.
+	if got := bob.omw; math.Abs(got-7.0/9) > 1e-9 {
+		t.Errorf("Bob OMW %v, want 7/9", got)
+	}
+}
+
+func TestGameWinRate(t *testing.T) {
+	s := &standing{}
+	s.addMatch("Bob", 2, 0, 1)
+	if got := s.gameWinRate(); math.Abs(got-7.0/9) > 1e-9 {
+		t.Errorf("2-0-1 game win rate %v, want 7/9", got)
+	}
+	if s.matchWins != 1 || s.points != matchWinPoints {
+		t.Errorf("2-0-1 counted as %d wins, %d points; want a match win", s.matchWins, s.points)
+	}
+}
+
+func TestLoadLeagueVersion(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr bool
+	}{
+		// Written before league files had a version.
+		{data: `{"players":[{"name":"Alice"}],"matches":null}`},
+		{data: fmt.Sprintf(`{"version":%d,"players":[{"name":"Alice"}]}`, leagueVersion)},
+		{data: fmt.Sprintf(`{"version":%d,"players":[{"name":"Alice"}]}`, leagueVersion+1), wantErr: true},
+		{data: `{"version":-1,"players":[{"name":"Alice"}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "league.json")
+		if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		l, err := loadLeague(path)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("loaded %s", tt.data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.data, err)
+			continue
+		}
+		if l.Version != leagueVersion || len(l.Players) != 1 {
+			t.Errorf("%s: got version %d with %d players", tt.data, l.Version, len(l.Players))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// staleLockAge is well past the longest a sync takes, so an older lock was
+// left behind by a process that died.
+const staleLockAge = 2 * time.Hour
+
+// lockFile takes an exclusive lock by creating path, which works on network
+// mounts where flock does not. The lock records who holds it, for the error
+// another process gets. Call the returned function to release it.
+func lockFile(path string) (func(), error) {
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			host, _ := os.Hostname()
+			fmt.Fprintf(f, "%s %d %s\n", host, os.Getpid(), time.Now().Format(time.RFC3339))
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		owner, _ := os.ReadFile(path)
+		info, statErr := os.Stat(path)
+		if attempt == 0 && statErr == nil && time.Since(info.ModTime()) > staleLockAge && breakStaleLock(path, owner) {
+			continue
+		}
+		return nil, fmt.Errorf("%s is locked by another process (%s); remove the file if that process is gone", path, strings.TrimSpace(string(owner)))
+	}
+}
+
+// breakStaleLock removes the stale lock at path, which held owner. Another
+// process may have broken it first and taken a fresh lock, so the lock is
+// moved aside and removed only if it still holds owner; a fresh one is put
+// back.
+func breakStaleLock(path string, owner []byte) bool {
+	aside := fmt.Sprintf("%s.stale-%d-%d", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, aside); err != nil {
+		return false
+	}
+	held, err := os.ReadFile(aside)
+	if err == nil && bytes.Equal(held, owner) {
+		os.Remove(aside)
+		return true
+	}
+	// Link fails rather than replacing a lock taken since the rename.
+	if os.Link(aside, path) == nil {
+		os.Remove(aside)
+	}
+	return false
+}
+
+// replaceFile writes data under a temporary name and renames it over path,
+// so an interrupted write leaves the old file in place and concurrent
+// writers never share a temporary file.
+func replaceFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	return nil
+}
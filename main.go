@@ -1,43 +1,43 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
+	"math/rand/v2"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/cloudsmyth/tradingcardsearch/render"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
 )
 
-type ScryfallResponse struct {
-	Object     string `json:"object"`
-	TotalCards int    `json:"total_cards"`
-	Data       []Card `json:"data"`
+var purchaseStores = []struct{ key, name string }{
+	{"tcgplayer", "TCGplayer"},
+	{"cardmarket", "Cardmarket"},
+	{"cardhoarder", "Cardhoarder"},
 }
 
-type Card struct {
-	Name       string   `json:"name"`
-	ManaCost   string   `json:"mana_cost"`
-	TypeLine   string   `json:"type_line"`
-	OracleText string   `json:"oracle_text"`
-	Power      string   `json:"power"`
-	Toughness  string   `json:"toughness"`
-	Colors     []string `json:"colors"`
-	SetName    string   `json:"set_name"`
-	Rarity     string   `json:"rarity"`
+var layoutLabels = map[string]string{
+	"host":    "Host (its host ability triggers when it enters)",
+	"augment": "Augment (combines with a host creature)",
+	"sticker": "Sticker sheet",
 }
 
-const (
-	scryfallAPI    = "https://api.scryfall.com/cards/search"
-	rateLimitDelay = 100 * time.Millisecond
-)
+// Terminals at least this wide show the highlighted card beside the results.
+const splitMinWidth = 100
+
+// Scryfall hides planes, phenomena, and schemes ("extras") unless asked.
+var extraTypePattern = regexp.MustCompile(`(?i)\b(t|type):"?(plane|phenomenon|scheme|ongoing)\b`)
 
 var (
 	titleStyle = lipgloss.NewStyle().
@@ -69,23 +69,50 @@ const (
 	searchView viewMode = iota
 	resultsView
 	detailView
+	statsView
 )
 
 type model struct {
 	textInput    textinput.Model
-	cards        []Card
+	cards        []scryfall.Card
+	query        string
+	order        []sortKey
+	total        int
+	nextPage     string
+	history      []searchState
 	list         list.Model
-	selectedCard *Card
+	selectedCard *scryfall.Card
 	mode         viewMode
 	searching    bool
 	err          error
+	notice       string
+	suggestions  []string
+	pick         string
+	startup      tea.Cmd
+	recent       []string
+	recentPos    int
+	draft        string
+	reversing    bool
+	reverse      string
+	reverseMatch int
 	width        int
 	height       int
 }
 
+type searchState struct {
+	query    string
+	order    []sortKey
+	cards    []scryfall.Card
+	total    int
+	nextPage string
+}
+
 type searchResultMsg struct {
-	cards []Card
-	err   error
+	searchState
+	refine      bool
+	more        bool
+	suggestions []string
+	err         error
 }
 
 func initialModel() model {
@@ -94,9 +121,13 @@ func initialModel() model {
 	ti.Focus()
 	ti.CharLimit = 100
 	ti.Width = 50
+	ti.ShowSuggestions = true
 
+	recent := loadHistory()
 	return model{
 		textInput: ti,
+		recent:    recent,
+		recentPos: len(recent),
 		mode:      searchView,
 		width:     80,
 		height:    24,
@@ -104,7 +135,15 @@ func initialModel() model {
 }
 
 func (m model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.startup)
+}
+
+// listWidth leaves the right side of wide terminals for the detail pane.
+func (m model) listWidth() int {
+	if m.width >= splitMinWidth {
+		return m.width * 2 / 5
+	}
+	return m.width
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -114,13 +153,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.width = msg.Width
 			m.height = msg.Height
 			if m.mode == resultsView {
-				m.list.SetSize(m.width, m.height-10)
+				m.list.SetSize(m.listWidth(), m.height-10)
 			}
 		}
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
+		key := msg.String()
+		if m.reversing {
+			return m.reverseSearch(msg), nil
+		}
+		if m.mode == resultsView && m.list.FilterState() != list.Filtering && len(key) == 1 && key >= "0" && key <= "9" {
+			m.pick += key
+			return m, nil
+		}
+
+		switch key {
 		case "ctrl+c", "q":
 			if m.mode == searchView {
 				return m, tea.Quit
@@ -131,7 +179,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case "esc":
-			if m.mode == detailView {
+			if m.pick != "" {
+				m.pick = ""
+				return m, nil
+			}
+			if m.mode == detailView || m.mode == statsView {
 				m.mode = resultsView
 				return m, nil
 			} else if m.mode == resultsView {
@@ -140,21 +192,135 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
+		case "r":
+			if m.mode == resultsView && m.list.FilterState() != list.Filtering {
+				m.mode = searchView
+				m.textInput.SetValue("refine ")
+				m.textInput.CursorEnd()
+				m.textInput.Focus()
+				return m, nil
+			}
+
+		case "s":
+			if m.mode == resultsView && m.list.FilterState() != list.Filtering {
+				m.mode = statsView
+				return m, nil
+			}
+
+		case "n":
+			if m.mode == resultsView && m.list.FilterState() != list.Filtering && m.nextPage != "" && !m.searching {
+				m.searching = true
+				m.err = nil
+				return m, nextPageCards(m.current())
+			}
+
+		case "up", "down":
+			if m.mode == searchView && len(m.textInput.MatchedSuggestions()) == 0 {
+				if key == "up" {
+					m.browseHistory(-1)
+				} else {
+					m.browseHistory(1)
+				}
+				return m, nil
+			}
+
+		case "ctrl+r":
+			if m.mode == searchView && len(m.recent) > 0 {
+				m.reversing = true
+				m.reverse = ""
+				m.reverseMatch = len(m.recent) - 1
+				return m, nil
+			}
+
+		case "tab":
+			value := strings.TrimSpace(m.textInput.Value())
+			if m.mode == searchView && value != "" && len(m.textInput.MatchedSuggestions()) == 0 {
+				return m, autocompleteNames(value, true)
+			}
+
+		case "backspace":
+			if m.mode == resultsView && m.list.FilterState() != list.Filtering && len(m.history) > 0 {
+				m.back()
+				return m, nil
+			}
+
 		case "enter":
 			if m.mode == searchView && !m.searching {
-				query := m.textInput.Value()
-				if query != "" {
+				m.notice = ""
+				m.remember(strings.TrimSpace(m.textInput.Value()))
+				input, order, err := splitSortOption(strings.TrimSpace(m.textInput.Value()))
+				if err != nil {
+					m.err = err
+					return m, nil
+				}
+				switch {
+				case input == "stats":
+					if len(m.cards) == 0 {
+						m.err = fmt.Errorf("no results to summarize; run a search first")
+						return m, nil
+					}
+					m.err = nil
+					m.mode = statsView
+					return m, nil
+				case input == "share":
+					if m.query == "" {
+						m.err = fmt.Errorf("nothing to share yet; run a search first")
+						return m, nil
+					}
+					m.err = nil
+					m.notice = scryfallSearchURL(m.query, m.order)
+					return m, nil
+				case input == "back":
+					if len(m.history) == 0 {
+						m.err = fmt.Errorf("no earlier results to go back to")
+						return m, nil
+					}
+					m.err = nil
+					m.back()
+					return m, nil
+				case strings.HasPrefix(input, "suggest "):
+					m.err = nil
+					return m, autocompleteNames(strings.TrimSpace(strings.TrimPrefix(input, "suggest ")), false)
+				case input == "refine" || strings.HasPrefix(input, "refine "):
+					if m.query == "" {
+						m.err = fmt.Errorf("nothing to refine yet; run a search first")
+						return m, nil
+					}
+					filter := strings.TrimSpace(strings.TrimPrefix(input, "refine"))
+					if filter == "" {
+						m.err = fmt.Errorf("usage: refine <query>")
+						return m, nil
+					}
+					if order == nil {
+						order = m.order
+					}
+					m.searching = true
+					m.err = nil
+					return m, refineCards(m.query, filter, order)
+				case input != "":
+					if order == nil {
+						order = defaultSort
+					}
 					m.searching = true
 					m.err = nil
-					return m, searchCards(query)
+					return m, searchCards(input, order)
 				}
 			} else if m.mode == resultsView {
-				if len(m.cards) > 0 {
-					selected := m.list.Index()
-					if selected < len(m.cards) {
-						m.selectedCard = &m.cards[selected]
-						m.mode = detailView
+				if m.pick != "" {
+					n, _ := strconv.Atoi(m.pick)
+					m.pick = ""
+					if n < 1 || n > len(m.cards) {
+						m.err = fmt.Errorf("no card #%d; pick 1-%d", n, len(m.cards))
+						return m, nil
 					}
+					m.err = nil
+					m.selectedCard = &m.cards[n-1]
+					m.mode = detailView
+					return m, nil
+				}
+				if item, ok := m.list.SelectedItem().(cardItem); ok {
+					m.selectedCard = &m.cards[item.number-1]
+					m.mode = detailView
 				}
 				return m, nil
 			}
@@ -163,15 +329,41 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case searchResultMsg:
 		m.searching = false
 		m.err = msg.err
-		if msg.err == nil && len(msg.cards) > 0 {
-			m.cards = msg.cards
-			m.mode = resultsView
-			items := make([]list.Item, len(msg.cards))
-			for i, card := range msg.cards {
-				items[i] = cardItem{card: card}
+		if msg.err == nil && msg.refine && len(msg.cards) == 0 {
+			m.err = fmt.Errorf("no cards match %s", msg.query)
+		}
+		if m.err == nil {
+			selected := 0
+			switch {
+			case msg.more:
+				selected = m.list.Index()
+			case msg.refine:
+				m.history = append(m.history, m.current())
+				m.textInput.SetValue("")
+			default:
+				m.history = nil
 			}
-			m.list = list.New(items, list.NewDefaultDelegate(), m.width, m.height-10)
-			m.list.Title = fmt.Sprintf("Found %d cards", len(msg.cards))
+			m.setResults(msg.searchState)
+			m.list.Select(selected)
+			m.suggestions = msg.suggestions
+		}
+		return m, nil
+
+	case suggestionsMsg:
+		m.err = msg.err
+		if msg.err != nil {
+			return m, nil
+		}
+		if msg.fill {
+			m.textInput.SetSuggestions(msg.names)
+		}
+		switch {
+		case len(msg.names) == 0:
+			m.notice = fmt.Sprintf("No card names match %q", msg.text)
+		case msg.fill:
+			m.notice = "Suggestions: " + strings.Join(msg.names, " • ") + "\n(Tab to complete, ↑/↓ to cycle)"
+		default:
+			m.notice = "Suggestions: " + strings.Join(msg.names, " • ")
 		}
 		return m, nil
 	}
@@ -185,6 +377,68 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// reverseSearch handles keys while searching history with Ctrl+R, as in a
+// shell: typing narrows the match, Ctrl+R steps to older matches, Enter
+// keeps the match in the search box, and Esc restores the input.
+func (m model) reverseSearch(msg tea.KeyMsg) model {
+	switch msg.Type {
+	case tea.KeyCtrlR:
+		if i := m.findHistory(m.reverseMatch); i >= 0 {
+			m.reverseMatch = i
+		}
+		return m
+	case tea.KeyBackspace:
+		if m.reverse != "" {
+			m.reverse = string([]rune(m.reverse)[:len([]rune(m.reverse))-1])
+			m.reverseMatch = m.findHistory(len(m.recent))
+		}
+		return m
+	case tea.KeyRunes, tea.KeySpace:
+		m.reverse += string(msg.Runes)
+		m.reverseMatch = m.findHistory(len(m.recent))
+		return m
+	case tea.KeyEsc, tea.KeyCtrlG, tea.KeyCtrlC:
+		m.reversing = false
+		return m
+	}
+
+	m.reversing = false
+	if m.reverseMatch >= 0 {
+		m.recentPos = m.reverseMatch
+		m.textInput.SetValue(m.recent[m.reverseMatch])
+		m.textInput.CursorEnd()
+	}
+	return m
+}
+
+func (m *model) current() searchState {
+	return searchState{query: m.query, order: m.order, cards: m.cards, total: m.total, nextPage: m.nextPage}
+}
+
+func (m *model) setResults(s searchState) {
+	m.query = s.query
+	m.order = s.order
+	m.cards = s.cards
+	m.total = s.total
+	m.nextPage = s.nextPage
+	m.mode = resultsView
+	items := make([]list.Item, len(s.cards))
+	for i, card := range s.cards {
+		items[i] = cardItem{number: i + 1, card: card}
+	}
+	m.list = list.New(items, list.NewDefaultDelegate(), m.listWidth(), m.height-10)
+	m.list.Title = fmt.Sprintf("Found %d cards", len(s.cards))
+	if s.nextPage != "" {
+		m.list.Title = fmt.Sprintf("Showing %d of %d cards", len(s.cards), s.total)
+	}
+}
+
+func (m *model) back() {
+	prev := m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	m.setResults(prev)
+}
+
 func (m model) View() string {
 	switch m.mode {
 	case searchView:
@@ -193,6 +447,8 @@ func (m model) View() string {
 		return m.resultsView()
 	case detailView:
 		return m.detailView()
+	case statsView:
+		return renderStats(m.cards) + "\n" + helpStyle.Render("Press esc to go back • q to quit")
 	}
 	return ""
 }
@@ -206,6 +462,14 @@ func (m model) searchView() string {
 	b.WriteString(inputStyle.Render(m.textInput.View()))
 	b.WriteString("\n\n")
 
+	if m.reversing {
+		match := ""
+		if m.reverseMatch >= 0 {
+			match = m.recent[m.reverseMatch]
+		}
+		b.WriteString(fmt.Sprintf("(reverse-i-search)`%s': %s\n\n", m.reverse, match))
+	}
+
 	if m.searching {
 		b.WriteString("Searching...\n")
 	}
@@ -215,7 +479,19 @@ func (m model) searchView() string {
 		b.WriteString("\n")
 	}
 
-	b.WriteString(helpStyle.Render("Press Enter to search • q to quit"))
+	if m.notice != "" {
+		b.WriteString(m.notice)
+		b.WriteString("\n\n")
+	}
+
+	help := "Press Enter to search • ↑/↓ or Ctrl+R for history • Tab to complete a card name • suggest <text> to list names"
+	if m.query != "" {
+		help += " • stats to summarize • refine <query> to narrow the last results • share for a Scryfall link"
+	}
+	if len(m.history) > 0 {
+		help += " • back to undo a refine"
+	}
+	b.WriteString(helpStyle.Render(help + " • q to quit"))
 
 	return b.String()
 }
@@ -226,13 +502,45 @@ func (m model) resultsView() string {
 	if len(m.cards) == 0 {
 		b.WriteString(titleStyle.Render("No cards found"))
 		b.WriteString("\n\n")
+		if hint := didYouMean(m.suggestions); hint != "" {
+			b.WriteString(hint)
+			b.WriteString("\n\n")
+		}
 		b.WriteString(helpStyle.Render("Press esc to search again • q to quit"))
 		return b.String()
 	}
 
-	b.WriteString(m.list.View())
+	if item, ok := m.list.SelectedItem().(cardItem); ok && m.width >= splitMinWidth {
+		detail := lipgloss.NewStyle().
+			Width(m.width - m.listWidth() - 2).
+			MaxHeight(m.height - 10).
+			PaddingLeft(2).
+			Render(cardDetail(&m.cards[item.number-1]))
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), detail))
+	} else {
+		b.WriteString(m.list.View())
+	}
+	b.WriteString("\n")
+	b.WriteString(cardDetailStyle.Render("Query: " + m.query))
 	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("↑/↓: navigate • Enter: view details • esc: back • q: quit"))
+	if m.searching {
+		b.WriteString("Loading the next page...\n")
+	}
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n")
+	}
+	if m.pick != "" {
+		b.WriteString(fmt.Sprintf("Open card #%s (Enter to confirm, esc to cancel)\n", m.pick))
+	}
+	help := "↑/↓ or a number: choose • /: filter • Enter: view details • s: stats • r: refine"
+	if m.nextPage != "" {
+		help += " • n: next page"
+	}
+	if len(m.history) > 0 {
+		help += " • backspace: undo refine"
+	}
+	b.WriteString(helpStyle.Render(help + " • esc: back • q: quit"))
 
 	return b.String()
 }
@@ -241,44 +549,179 @@ func (m model) detailView() string {
 	if m.selectedCard == nil {
 		return "No card selected"
 	}
+	return cardDetail(m.selectedCard) + "\n" + helpStyle.Render("Press esc to go back • q to quit")
+}
 
+func cardDetail(card *scryfall.Card) string {
 	var b strings.Builder
-	card := m.selectedCard
 
-	b.WriteString(cardTitleStyle.Render(fmt.Sprintf("%s %s", card.Name, card.ManaCost)))
+	b.WriteString(cardTitleStyle.Render(card.Name) + " " + manaCost(card.ManaCost))
 	b.WriteString("\n\n")
 
-	b.WriteString(cardDetailStyle.Render("Type: "))
-	b.WriteString(card.TypeLine)
-	b.WriteString("\n\n")
+	for _, face := range card.Faces() {
+		if len(card.CardFaces) > 0 {
+			b.WriteString(cardTitleStyle.Render(face.Name) + " " + manaCost(face.ManaCost))
+			b.WriteString("\n")
+		}
 
-	if card.OracleText != "" {
-		b.WriteString(cardDetailStyle.Render("Text:\n"))
-		b.WriteString(wrapText(card.OracleText, 70))
+		b.WriteString(cardDetailStyle.Render("Type: "))
+		b.WriteString(face.TypeLine)
 		b.WriteString("\n\n")
+
+		if face.OracleText != "" {
+			b.WriteString(cardDetailStyle.Render("Text:\n"))
+			b.WriteString(wrapText(face.OracleText, 70))
+			b.WriteString("\n\n")
+		}
+
+		if face.Power != "" && face.Toughness != "" {
+			b.WriteString(cardDetailStyle.Render("Power/Toughness: "))
+			b.WriteString(fmt.Sprintf("%s/%s\n\n", face.Power, face.Toughness))
+		}
+
+		if face.FlavorText != "" {
+			b.WriteString(cardDetailStyle.Render(wrapText(face.FlavorText, 70)))
+			b.WriteString("\n\n")
+		}
 	}
 
-	if card.Power != "" && card.Toughness != "" {
-		b.WriteString(cardDetailStyle.Render("Power/Toughness: "))
-		b.WriteString(fmt.Sprintf("%s/%s\n\n", card.Power, card.Toughness))
+	if len(card.AttractionLights) > 0 {
+		lights := make([]string, len(card.AttractionLights))
+		for i, n := range card.AttractionLights {
+			lights[i] = fmt.Sprint(n)
+		}
+		b.WriteString(cardDetailStyle.Render("Attraction lights: "))
+		b.WriteString(strings.Join(lights, ", "))
+		b.WriteString("\n\n")
+	}
+
+	if label := layoutLabels[card.Layout]; label != "" {
+		b.WriteString(cardDetailStyle.Render("Layout: "))
+		b.WriteString(label)
+		b.WriteString("\n")
+	}
+
+	if card.BorderColor == "silver" || card.BorderColor == "gold" {
+		b.WriteString(cardDetailStyle.Render("Border: "))
+		b.WriteString(card.BorderColor)
+		b.WriteString("\n")
+	}
+
+	if isSpecialProduct(card) {
+		b.WriteString(cardDetailStyle.Render("Product: "))
+		b.WriteString("special printing (oversized, memorabilia, or art card)\n")
+	}
+
+	if card.SecurityStamp == "acorn" {
+		b.WriteString(cardDetailStyle.Render("Stamp: "))
+		b.WriteString("acorn (not tournament legal)\n")
 	}
 
 	b.WriteString(cardDetailStyle.Render("Set: "))
-	b.WriteString(fmt.Sprintf("%s (%s)\n", card.SetName, card.Rarity))
+	rarity := card.Rarity
+	if useColor {
+		rarity = render.Rarity(rarity, rarity)
+	}
+	b.WriteString(fmt.Sprintf("%s #%s (%s)\n", card.SetName, card.CollectorNumber, rarity))
 
-	if len(card.Colors) > 0 {
+	if n := ownedCopies(card.Name); n > 0 {
+		b.WriteString(cardDetailStyle.Render("Collection: "))
+		b.WriteString(ownedLabel(n))
+		b.WriteString("\n")
+	}
+
+	if card.Artist != "" {
+		b.WriteString(cardDetailStyle.Render("Artist: "))
+		b.WriteString(card.Artist)
+		b.WriteString("\n")
+	}
+
+	if len(card.Legalities) > 0 && len(legalityFormats) > 0 {
+		parts := make([]string, 0, len(legalityFormats))
+		for _, format := range legalityFormats {
+			status := card.Legalities[format]
+			if status == "" {
+				status = "unknown"
+			}
+			parts = append(parts, fmt.Sprintf("%s %s", format, strings.ReplaceAll(status, "_", " ")))
+		}
+		b.WriteString(cardDetailStyle.Render("Legality: "))
+		b.WriteString(strings.Join(parts, " • "))
+		b.WriteString("\n")
+	}
+
+	if showPrices {
+		b.WriteString(cardDetailStyle.Render("Prices: "))
+		if parts := priceList(card.Prices); len(parts) > 0 {
+			b.WriteString(strings.Join(parts, " • "))
+		} else {
+			b.WriteString("none listed")
+		}
+		b.WriteString("\n")
+		for _, shop := range purchaseStores {
+			if uri := card.PurchaseURIs[shop.key]; uri != "" {
+				b.WriteString(cardDetailStyle.Render(shop.name + ": "))
+				b.WriteString(uri)
+				b.WriteString("\n")
+			}
+		}
+	}
+
+	if colors := cardColors(card); len(colors) > 0 {
 		b.WriteString(cardDetailStyle.Render("Colors: "))
-		b.WriteString(strings.Join(card.Colors, ", "))
+		b.WriteString(colorList(colors))
+		b.WriteString("\n")
+	}
+	if len(card.ColorIdentity) > 0 {
+		b.WriteString(cardDetailStyle.Render("Color identity: "))
+		b.WriteString(colorList(card.ColorIdentity))
 		b.WriteString("\n")
 	}
 
-	b.WriteString("\n")
-	b.WriteString(helpStyle.Render("Press esc to go back • q to quit"))
+	if card.ScryfallURI != "" {
+		b.WriteString(cardDetailStyle.Render("Scryfall: "))
+		b.WriteString(card.ScryfallURI)
+		b.WriteString("\n")
+	}
+	if card.RulingsURI != "" {
+		b.WriteString(cardDetailStyle.Render("Rulings: "))
+		b.WriteString(card.RulingsURI)
+		b.WriteString("\n")
+	}
+	for _, size := range []string{"normal", "large", "art_crop"} {
+		if uri := card.Image(size); uri != "" {
+			b.WriteString(cardDetailStyle.Render("Image (" + strings.ReplaceAll(size, "_", " ") + "): "))
+			b.WriteString(uri)
+			b.WriteString("\n")
+		}
+	}
 
 	return b.String()
 }
 
+func manaCost(cost string) string {
+	if useColor {
+		return render.Mana(cost)
+	}
+	return cost
+}
+
+func colorList(colors []string) string {
+	if useColor {
+		return render.ColorIdentity(colors) + " " + strings.Join(colors, ", ")
+	}
+	return strings.Join(colors, ", ")
+}
+
 func wrapText(text string, width int) string {
+	paragraphs := strings.Split(text, "\n")
+	for i, p := range paragraphs {
+		paragraphs[i] = wrapParagraph(p, width)
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+func wrapParagraph(text string, width int) string {
 	words := strings.Fields(text)
 	if len(words) == 0 {
 		return text
@@ -288,7 +731,7 @@ func wrapText(text string, width int) string {
 	var currentLine strings.Builder
 
 	for _, word := range words {
-		if currentLine.Len()+len(word)+1 > width {
+		if currentLine.Len() > 0 && currentLine.Len()+len(word)+1 > width {
 			lines = append(lines, currentLine.String())
 			currentLine.Reset()
 		}
@@ -306,58 +749,345 @@ func wrapText(text string, width int) string {
 }
 
 type cardItem struct {
-	card Card
+	number int
+	card   scryfall.Card
 }
 
-func (i cardItem) Title() string       { return fmt.Sprintf("%s %s", i.card.Name, i.card.ManaCost) }
-func (i cardItem) Description() string { return i.card.TypeLine }
+func (i cardItem) Title() string {
+	if uniquePrints {
+		return fmt.Sprintf("%d. %s %s (%s #%s)", i.number, i.card.Name, manaCost(i.card.ManaCost), strings.ToUpper(i.card.Set), i.card.CollectorNumber)
+	}
+	return fmt.Sprintf("%d. %s %s", i.number, i.card.Name, manaCost(i.card.ManaCost))
+}
+func (i cardItem) Description() string {
+	desc := i.card.TypeLine
+	if showPrices {
+		desc += " • " + priceLabel(i.card.Prices)
+	}
+	if n := ownedCopies(i.card.Name); n > 0 {
+		desc += " • " + ownedLabel(n)
+	}
+	return desc
+}
 func (i cardItem) FilterValue() string { return i.card.Name }
 
-func searchCards(query string) tea.Cmd {
+func searchCards(query string, order []sortKey) tea.Cmd {
 	return func() tea.Msg {
-		params := url.Values{}
-		params.Add("q", query)
-		params.Add("order", "name")
+		s, err := fetchResults(query, order)
+		msg := searchResultMsg{searchState: s, err: err}
+		if err == nil && len(s.cards) == 0 {
+			msg.suggestions = suggestNames(query)
+		}
+		return msg
+	}
+}
 
-		reqURL := fmt.Sprintf("%s?%s", scryfallAPI, params.Encode())
+func refineCards(query, filter string, order []sortKey) tea.Cmd {
+	return func() tea.Msg {
+		s, err := fetchResults(fmt.Sprintf("(%s) (%s)", query, filter), order)
+		return searchResultMsg{searchState: s, refine: true, err: err}
+	}
+}
 
-		resp, err := http.Get(reqURL)
+func nextPageCards(s searchState) tea.Cmd {
+	return func() tea.Msg {
+		page, err := api.Next(&scryfall.List{HasMore: true, NextPage: s.nextPage})
 		if err != nil {
-			return searchResultMsg{err: fmt.Errorf("failed to make request: %w", err)}
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == 429 {
-			return searchResultMsg{err: fmt.Errorf("rate limited by Scryfall API")}
+			return searchResultMsg{err: err}
 		}
+		s.cards = append(append([]scryfall.Card(nil), s.cards...), keepCards(s.query, page.Data)...)
+		s.nextPage = page.NextPage
+		sortCards(s.cards, s.order)
+		return searchResultMsg{searchState: s, more: true}
+	}
+}
 
-		if resp.StatusCode != 200 {
-			body, _ := io.ReadAll(resp.Body)
-			return searchResultMsg{err: fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))}
-		}
+func fetchCards(query string, order []sortKey) ([]scryfall.Card, error) {
+	s, err := fetchResults(query, order)
+	return s.cards, err
+}
 
-		body, err := io.ReadAll(resp.Body)
+// fetchResults returns the first page of a search, or every page when
+// fetchAll is set. nextPage is left empty once nothing more is available.
+func fetchResults(query string, order []sortKey) (searchState, error) {
+	s := searchState{query: query, order: order}
+	if offline {
+		cards, err := localSearch(withGlobalFilters(query), extraTypePattern.MatchString(query))
 		if err != nil {
-			return searchResultMsg{err: fmt.Errorf("failed to read response: %w", err)}
+			return searchState{}, err
 		}
+		s.cards = keepCards(query, cards)
+		s.total = len(s.cards)
+		// Bulk data is in no useful order, so ties fall back to the name.
+		sortCards(s.cards, append(append([]sortKey(nil), order...), sortKey{field: "name"}))
+		return s, nil
+	}
 
-		var result ScryfallResponse
-		if err := json.Unmarshal(body, &result); err != nil {
-			return searchResultMsg{err: fmt.Errorf("failed to parse JSON: %w", err)}
+	field, dir := scryfallOrder(order)
+	opts := scryfall.SearchOptions{
+		Order:         field,
+		Dir:           dir,
+		IncludeExtras: extraTypePattern.MatchString(query),
+	}
+	if uniquePrints {
+		opts.Unique = "prints"
+	}
+	page, err := api.Search(withGlobalFilters(query), opts)
+	for err == nil {
+		s.cards = append(s.cards, keepCards(query, page.Data)...)
+		s.total = page.TotalCards
+		s.nextPage = page.NextPage
+		if !fetchAll || !page.HasMore {
+			break
 		}
+		page, err = api.Next(page)
+	}
+	if err != nil {
+		return searchState{}, err
+	}
+
+	sortCards(s.cards, order)
+	return s, nil
+}
+
+func keepCards(query string, cards []scryfall.Card) []scryfall.Card {
+	if includeOversized || extraTypePattern.MatchString(query) {
+		return cards
+	}
+	return withoutSpecialProducts(cards)
+}
+
+// isSpecialProduct reports printings that are not playable cards: oversized
+// cards, memorabilia such as gold-bordered championship decks, and art cards.
+func isSpecialProduct(card *scryfall.Card) bool {
+	return card.Oversized || card.SetType == "memorabilia" || card.Layout == "art_series"
+}
 
-		time.Sleep(rateLimitDelay)
+func withoutSpecialProducts(cards []scryfall.Card) []scryfall.Card {
+	kept := cards[:0]
+	for _, card := range cards {
+		if !isSpecialProduct(&card) {
+			kept = append(kept, card)
+		}
+	}
+	return kept
+}
 
-		return searchResultMsg{cards: result.Data}
+func withGlobalFilters(query string) string {
+	if len(globalFilters) == 0 {
+		return query
+	}
+	if strings.TrimSpace(query) == "" {
+		return strings.Join(globalFilters, " ")
 	}
+	return fmt.Sprintf("(%s) %s", query, strings.Join(globalFilters, " "))
+}
+
+var api = scryfall.NewClient()
+
+var (
+	globalFilters    []string
+	includeOversized bool
+	fetchAll         bool
+	seed             uint64
+	showPrices       bool
+	uniquePrints     bool
+	legalityFormats  []string
+	useColor         bool
+)
+
+// newRand returns the generator every randomized feature draws from, so a
+// -seed reproduces the same shuffles and rolls.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+var commands = map[string]func(args []string) error{
+	"telegram":   runTelegram,
+	"twitch":     runTwitch,
+	"irc":        runIRC,
+	"matrix":     runMatrix,
+	"calendar":   runCalendar,
+	"get":        runGet,
+	"league":     runLeague,
+	"planechase": runPlanechase,
+	"archenemy":  runArchenemy,
+	"basics":     runBasics,
+	"deck":       runDeck,
+	"decks":      runDecks,
+	"doctor":     runDoctor,
+	"build":      runBuild,
+	"collection": runCollection,
+	"random":     runRandom,
+	"sets":       runSets,
+	"setup":      runSetup,
+	"set":        runSet,
+	"sync":       runSync,
+	"url":        runURL,
+	"share":      runShare,
 }
 
 func main() {
+	sortSpec := flag.String("sort", "", "sort results by comma-separated keys, e.g. cmc,name or price:desc,set")
+	orderField := flag.String("order", "", "sort results by one key, e.g. name, cmc, price, released, edhrec, or rarity; -sort keys break ties")
+	orderDir := flag.String("dir", "", "direction for -order: asc or desc")
+	flag.BoolVar(&offline, "offline", false, "search the bulk data saved by the sync command instead of the Scryfall API")
+	flag.StringVar(&cacheDir, "cache-dir", "", "directory for the bulk data and search index (defaults to mtg-go-search in the user cache directory)")
+	flag.StringVar(&currency, "currency", "usd", "price to show first: usd, eur, or tix")
+	legality := flag.String("legality", "standard,modern,commander,pauper", "comma-separated formats whose legality the detail view shows")
+	format := flag.String("format", "", "only search cards legal in this format, e.g. commander or modern")
+	flag.BoolVar(&showPrices, "prices", false, "show prices and purchase links in results and card details")
+	flag.Uint64Var(&seed, "seed", 0, "seed for shuffles and die rolls, to reproduce a game (0 picks one at random)")
+	flag.BoolVar(&uniquePrints, "prints", false, "list every printing of each card, grouped by card, instead of one per card")
+	flag.BoolVar(&fetchAll, "all", false, "fetch every page of results instead of only the first 175 cards")
+	flag.BoolVar(&includeOversized, "include-oversized", false, "include oversized cards, memorabilia, and art cards in results")
+	flag.StringVar(&outputFormat, "output", "compact", "one-shot search output: "+strings.Join(render.Formats, ", "))
+	var parts queryParts
+	flag.StringVar(&parts.Type, "type", "", "search for a card type, e.g. creature or \"legendary artifact\" (adds t: terms)")
+	flag.StringVar(&parts.Colors, "color", "", "search for colors, letters from wubrg or c (adds c:)")
+	flag.StringVar(&parts.ManaValue, "cmc", "", "search for a mana value: 3, <=3, or 2-4 (adds cmc terms)")
+	flag.StringVar(&parts.Text, "text", "", "search rules text for a phrase (adds o:)")
+	flag.StringVar(&parts.Rarity, "rarity", "", "search for a rarity: common, uncommon, rare, or mythic (adds r:)")
+	noColor := flag.Bool("no-color", false, "turn off colors and mana symbols (also set by the NO_COLOR environment variable)")
+	tui := flag.Bool("tui", false, "open the interactive browser even when a query is given, starting with its results")
+	silverBorder := flag.String("silver-border", "include", "Un-cards (silver border, acorn stamp): include, exclude, or only")
+	flag.Parse()
+
+	explicit := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if path, err := configPath(); err == nil {
+		if firstRun(path) {
+			if err := runSetup(nil); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := applyConfig(path, explicit); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+	if !slices.Contains(currencies, currency) {
+		fmt.Fprintf(os.Stderr, "Error: -currency must be one of %s\n", strings.Join(currencies, ", "))
+		os.Exit(2)
+	}
+
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+
+	useColor = !*noColor && os.Getenv("NO_COLOR") == ""
+	if !useColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	render.Color = useColor && stdoutIsTerminal()
+
+	if uniquePrints && !explicit["output"] {
+		outputFormat = "prints"
+	}
+
+	var err error
+	if defaultSort, err = parseSortKeys(*sortSpec); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	if *orderField != "" || *orderDir != "" {
+		if defaultSort, err = withOrder(defaultSort, *orderField, *orderDir); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	switch *silverBorder {
+	case "include":
+	case "exclude":
+		globalFilters = append(globalFilters, "-is:funny")
+	case "only":
+		globalFilters = append(globalFilters, "is:funny")
+	default:
+		fmt.Printf("Error: -silver-border must be include, exclude, or only\n")
+		os.Exit(2)
+	}
+
+	for _, f := range strings.Split(strings.ToLower(*legality), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			legalityFormats = append(legalityFormats, f)
+		}
+	}
+	if *format != "" {
+		globalFilters = append(globalFilters, "f:"+strings.ToLower(*format))
+	}
+
+	if !render.Valid(outputFormat) {
+		fmt.Printf("Error: -output must be one of %s\n", strings.Join(render.Formats, ", "))
+		os.Exit(2)
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		if run, ok := commands[args[0]]; ok {
+			if err := run(args[1:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	query := strings.Join(flag.Args(), " ")
+	fromFlags, err := compileQuery(parts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if fromFlags != "" {
+		query = strings.TrimSpace(fromFlags + " " + query)
+	} else if query == "" && !stdinIsTerminal() {
+		if query, err = readQuery(os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		if query == "" {
+			fmt.Fprintln(os.Stderr, "Error: no query on standard input")
+			os.Exit(2)
+		}
+	}
+	m := initialModel()
+	if query != "" && *tui {
+		input, order, err := splitSortOption(query)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		if order == nil {
+			order = defaultSort
+		}
+		m.remember(query)
+		m.searching = true
+		m.startup = searchCards(input, order)
+	} else if query != "" {
+		if err := runSearch(query); errors.Is(err, errNoCards) {
+			fmt.Fprintf(os.Stderr, "No cards found for %q\n", query)
+			if input, _, err := splitSortOption(query); err == nil {
+				if hint := didYouMean(suggestNames(input)); hint != "" {
+					fmt.Fprintln(os.Stderr, hint)
+				}
+			}
+			os.Exit(1)
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if err := loadOwned(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; results will not show owned copies\n", err)
+	}
 	opts := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithInput(os.Stdin),
 	}
-	p := tea.NewProgram(initialModel(), opts...)
+	p := tea.NewProgram(m, opts...)
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
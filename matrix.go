@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	matrixSyncTimeout = 30 * time.Second
+	matrixMaxMessage  = 2000
+	matrixRetryDelay  = 5 * time.Second
+)
+
+type matrixSyncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []matrixEvent `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+		Invite map[string]json.RawMessage `json:"invite"`
+	} `json:"rooms"`
+}
+
+type matrixEvent struct {
+	Type    string `json:"type"`
+	Sender  string `json:"sender"`
+	EventID string `json:"event_id"`
+	Content struct {
+		MsgType string `json:"msgtype"`
+		Body    string `json:"body"`
+	} `json:"content"`
+}
+
+type matrixBot struct {
+	homeserver string
+	token      string
+	userID     string
+	client     *http.Client
+	cooldown   *botCooldown
+	txn        int64
+}
+
+func runMatrix(args []string) error {
+	fs := flag.NewFlagSet("matrix", flag.ExitOnError)
+	homeserver := fs.String("homeserver", "", "homeserver base URL, e.g. https://matrix.org")
+	token := fs.String("token", os.Getenv("MATRIX_ACCESS_TOKEN"), "access token (defaults to $MATRIX_ACCESS_TOKEN)")
+	rooms := fs.String("rooms", "", "comma-separated room IDs or aliases to join")
+	cooldown := fs.Duration("cooldown", 5*time.Second, "minimum time between replies in a room")
+	fs.Parse(args)
+
+	if *homeserver == "" {
+		return fmt.Errorf("no homeserver: pass -homeserver")
+	}
+	if *token == "" {
+		return fmt.Errorf("no access token: set MATRIX_ACCESS_TOKEN or pass -token")
+	}
+
+	bot := &matrixBot{
+		homeserver: strings.TrimRight(*homeserver, "/"),
+		token:      *token,
+		client:     &http.Client{Timeout: matrixSyncTimeout + 10*time.Second},
+		cooldown:   newBotCooldown(*cooldown),
+		txn:        time.Now().UnixNano(),
+	}
+
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := bot.do("GET", "/account/whoami", nil, &whoami); err != nil {
+		return err
+	}
+	bot.userID = whoami.UserID
+
+	for _, room := range strings.Split(*rooms, ",") {
+		room = strings.TrimSpace(room)
+		if room == "" {
+			continue
+		}
+		if err := bot.do("POST", "/join/"+url.PathEscape(room), map[string]any{}, nil); err != nil {
+			return fmt.Errorf("failed to join %s: %w", room, err)
+		}
+	}
+
+	// Skip the backlog so the bot only answers messages sent after it starts.
+	since, err := bot.sync("", 0)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("matrix bot running as %s", bot.userID)
+	for {
+		next, err := bot.sync(since, matrixSyncTimeout)
+		if err != nil {
+			log.Printf("matrix: %v", err)
+			time.Sleep(matrixRetryDelay)
+			continue
+		}
+		since = next
+	}
+}
+
+func (b *matrixBot) sync(since string, timeout time.Duration) (string, error) {
+	params := url.Values{}
+	params.Add("timeout", fmt.Sprint(timeout.Milliseconds()))
+	if since != "" {
+		params.Add("since", since)
+	}
+
+	var resp matrixSyncResponse
+	if err := b.do("GET", "/sync?"+params.Encode(), nil, &resp); err != nil {
+		return since, err
+	}
+	if since == "" {
+		return resp.NextBatch, nil
+	}
+
+	for roomID := range resp.Rooms.Invite {
+		if err := b.do("POST", "/rooms/"+url.PathEscape(roomID)+"/join", map[string]any{}, nil); err != nil {
+			log.Printf("matrix: join %s: %v", roomID, err)
+		}
+	}
+
+	for roomID, room := range resp.Rooms.Join {
+		for _, event := range room.Timeline.Events {
+			if event.Type != "m.room.message" || event.Sender == b.userID || event.Content.MsgType != "m.text" {
+				continue
+			}
+			b.handleMessage(roomID, event.Content.Body)
+		}
+	}
+	return resp.NextBatch, nil
+}
+
+func (b *matrixBot) handleMessage(roomID, text string) {
+	if !strings.HasPrefix(strings.TrimSpace(text), "!") && !cardRefPattern.MatchString(text) {
+		return
+	}
+	replies := botReplies(text, matrixMaxMessage)
+	if len(replies) == 0 || !b.cooldown.allow(roomID) {
+		return
+	}
+
+	b.txn++
+	path := fmt.Sprintf("/rooms/%s/send/m.room.message/%d", url.PathEscape(roomID), b.txn)
+	content := map[string]string{
+		"msgtype": "m.notice",
+		"body":    strings.Join(replies, "\n"),
+	}
+	if err := b.do("PUT", path, content, nil); err != nil {
+		log.Printf("matrix: send to %s: %v", roomID, err)
+	}
+}
+
+func (b *matrixBot) do(method, path string, payload any, out any) error {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequest(method, b.homeserver+"/_matrix/client/v3"+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("homeserver returned status %d: %s", resp.StatusCode, string(data))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	}
+	return nil
+}
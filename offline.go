@@ -0,0 +1,475 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+	"github.com/cloudsmyth/tradingcardsearch/store"
+)
+
+const bulkKind = "default-cards"
+
+var offline bool
+
+// Scryfall leaves these layouts out of searches unless extras are asked for.
+var extraLayouts = map[string]bool{
+	"planar":             true,
+	"scheme":             true,
+	"vanguard":           true,
+	"token":              true,
+	"double_faced_token": true,
+	"emblem":             true,
+}
+
+var localTermPattern = regexp.MustCompile(`(-?)(?:([a-zA-Z]+)(:|<=|>=|!=|=|<|>))?("([^"]*)"|\S+)`)
+
+var rarityNames = map[string]string{"c": "common", "u": "uncommon", "r": "rare", "m": "mythic"}
+
+type cardFilter func(card *scryfall.Card) bool
+
+const indexBatch = 1000
+
+// indexColumns maps query keys that match text to the store's columns.
+var indexColumns = map[string]string{
+	"":       "name",
+	"t":      "type_line",
+	"type":   "type_line",
+	"o":      "oracle_text",
+	"oracle": "oracle_text",
+	"a":      "artist",
+	"artist": "artist",
+}
+
+func cachePath(name string) (string, error) {
+	if cacheDir != "" {
+		return filepath.Join(cacheDir, name), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	return filepath.Join(dir, "mtg-go-search", name), nil
+}
+
+func bulkPath() (string, error) {
+	return cachePath(bulkKind + ".json")
+}
+
+func indexPath() (string, error) {
+	return cachePath("cards.db")
+}
+
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sync")
+		fmt.Fprintln(fs.Output(), "Downloads Scryfall's bulk card data for -offline searches.")
+	}
+	fs.Parse(args)
+
+	path, err := bulkPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	bulk, err := api.BulkData(bulkKind)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), bulkKind+"-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	fmt.Printf("Downloading %s (%.0f MB, updated %s)...\n", bulk.Type, float64(bulk.Size)/1e6, bulk.UpdatedAt)
+	if err := api.Download(bulk, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	fmt.Printf("Saved to %s\n", path)
+
+	fmt.Println("Building the search index...")
+	return buildIndex(path)
+}
+
+func buildIndex(bulk string) error {
+	path, err := indexPath()
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "cards-*.db")
+	if err != nil {
+		return fmt.Errorf("failed to create index file: %w", err)
+	}
+	tmp := f.Name()
+	f.Close()
+	defer os.Remove(tmp)
+
+	db, err := store.Open(tmp)
+	if err != nil {
+		return err
+	}
+	var batch []scryfall.Card
+	err = scanBulk(bulk, func(card scryfall.Card) error {
+		batch = append(batch, card)
+		if len(batch) < indexBatch {
+			return nil
+		}
+		err := db.Index(batch)
+		batch = batch[:0]
+		return err
+	})
+	if err == nil {
+		err = db.Index(batch)
+	}
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", path, err)
+	}
+	fmt.Printf("Indexed to %s\n", path)
+	return nil
+}
+
+func scanBulk(path string, fn func(card scryfall.Card) error) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no local card data; run the sync command first")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for dec.More() {
+		var card scryfall.Card
+		if err := dec.Decode(&card); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if err := fn(card); err != nil {
+			return err
+		}
+	}
+	// More reports false at the end of the file too, so check that the
+	// array was closed rather than cut short.
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// localSearch finds cards in the synced bulk data matching a subset of
+// Scryfall syntax, keeping the newest printing of each card as Scryfall's
+// default search does, or every printing with -prints. Text terms narrow the candidates through the index
+// when one exists; the rest of the query is checked card by card.
+func localSearch(query string, includeExtras bool) ([]scryfall.Card, error) {
+	filters, phrases, err := parseLocalQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []scryfall.Card
+	seen := map[string]int{}
+	keep := func(card scryfall.Card) error {
+		if (!includeExtras && extraLayouts[card.Layout]) || !matchesAll(&card, filters) {
+			return nil
+		}
+		key := card.OracleID
+		if key == "" || uniquePrints {
+			key = card.ID
+		}
+		if i, ok := seen[key]; ok {
+			if card.ReleasedAt > cards[i].ReleasedAt {
+				cards[i] = card
+			}
+			return nil
+		}
+		seen[key] = len(cards)
+		cards = append(cards, card)
+		return nil
+	}
+
+	if len(phrases) > 0 {
+		candidates, ok, err := queryIndex(strings.Join(phrases, " AND "))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			for _, card := range candidates {
+				keep(card)
+			}
+			return cards, nil
+		}
+	}
+
+	path, err := bulkPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := scanBulk(path, keep); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// openIndex keeps one read-only store open for the whole process, so bots
+// and the TUI share its connections. It is reopened when sync replaces the
+// file. Queries hold the read lock, so the old store is closed only once
+// the queries using it have finished.
+var openIndex struct {
+	sync.RWMutex
+	db      *store.Store
+	modTime time.Time
+}
+
+// queryIndex reports false when sync has not built an index yet.
+func queryIndex(q string) ([]scryfall.Card, bool, error) {
+	path, err := indexPath()
+	if err != nil {
+		return nil, false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	openIndex.RLock()
+	if openIndex.db == nil || !info.ModTime().Equal(openIndex.modTime) {
+		openIndex.RUnlock()
+		if err := reopenIndex(path, info.ModTime()); err != nil {
+			return nil, false, err
+		}
+		openIndex.RLock()
+	}
+	defer openIndex.RUnlock()
+	if openIndex.db == nil {
+		// Scan the bulk data until the next sync rebuilds the index.
+		return nil, false, nil
+	}
+
+	cards, err := openIndex.db.Query(q)
+	return cards, err == nil, err
+}
+
+// reopenIndex replaces the open store with the file at path, unless another
+// query has already opened that version. An outdated file leaves no store
+// open.
+func reopenIndex(path string, modTime time.Time) error {
+	openIndex.Lock()
+	defer openIndex.Unlock()
+
+	if openIndex.db != nil && modTime.Equal(openIndex.modTime) {
+		return nil
+	}
+	if openIndex.db != nil {
+		openIndex.db.Close()
+		openIndex.db = nil
+	}
+	db, err := store.OpenReadOnly(path)
+	if errors.Is(err, store.ErrOutdated) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	openIndex.db, openIndex.modTime = db, modTime
+	return nil
+}
+
+func matchesAll(card *scryfall.Card, filters []cardFilter) bool {
+	for _, f := range filters {
+		if !f(card) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLocalQuery supports names, t:, o:, c:, cmc/mv comparisons, e:, a:,
+// r:, f:, banned:, is:funny, and is:full, each optionally negated with "-". Terms are
+// always ANDed together. Text terms long enough for the index are also
+// returned as store phrases.
+func parseLocalQuery(query string) ([]cardFilter, []string, error) {
+	var b strings.Builder
+	quoted := false
+	for i, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case (r == '(' || r == ')') && !quoted:
+			if r == '(' && i > 0 && query[i-1] == '-' {
+				return nil, nil, fmt.Errorf("offline search does not support negated groups")
+			}
+			r = ' '
+		}
+		b.WriteRune(r)
+	}
+
+	var filters []cardFilter
+	var phrases []string
+	for _, m := range localTermPattern.FindAllStringSubmatch(b.String(), -1) {
+		neg, key, op, value := m[1] == "-", strings.ToLower(m[2]), m[3], m[4]
+		if strings.HasPrefix(value, `"`) {
+			value = m[5]
+		}
+		if key == "" && strings.EqualFold(value, "or") {
+			return nil, nil, fmt.Errorf("offline search does not support \"or\"")
+		}
+
+		f, err := localTerm(key, op, strings.ToLower(value))
+		if err != nil {
+			return nil, nil, err
+		}
+		if column, ok := indexColumns[key]; ok && !neg && (op == ":" || key == "") && len(value) >= 3 {
+			phrases = append(phrases, store.Phrase(column, value))
+		}
+		if neg {
+			inner := f
+			f = func(card *scryfall.Card) bool { return !inner(card) }
+		}
+		filters = append(filters, f)
+	}
+	return filters, phrases, nil
+}
+
+func localTerm(key, op, value string) (cardFilter, error) {
+	contains := func(field func(*scryfall.Card) string) cardFilter {
+		return func(card *scryfall.Card) bool { return strings.Contains(strings.ToLower(field(card)), value) }
+	}
+
+	switch key {
+	case "":
+		return contains(func(c *scryfall.Card) string { return c.Name }), nil
+	case "t", "type":
+		return contains(func(c *scryfall.Card) string { return c.TypeLine }), nil
+	case "o", "oracle":
+		return contains(func(c *scryfall.Card) string { return c.Text() }), nil
+	case "a", "artist":
+		return contains(func(c *scryfall.Card) string { return c.Artist }), nil
+	case "e", "s", "set":
+		return func(c *scryfall.Card) bool { return c.Set == value }, nil
+	case "r", "rarity":
+		if name, ok := rarityNames[value]; ok {
+			value = name
+		}
+		return func(c *scryfall.Card) bool { return c.Rarity == value }, nil
+	case "c", "color":
+		return colorTerm(op, value)
+	case "cmc", "mv", "manavalue":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mana value %q", value)
+		}
+		return func(c *scryfall.Card) bool { return compareOp(op, compareFloat(c.CMC, n)) }, nil
+	case "f", "format", "legal":
+		return func(c *scryfall.Card) bool {
+			status := c.Legalities[value]
+			return status == "legal" || status == "restricted"
+		}, nil
+	case "banned":
+		return func(c *scryfall.Card) bool { return c.Legalities[value] == "banned" }, nil
+	case "is":
+		switch value {
+		case "funny":
+			return func(c *scryfall.Card) bool { return c.SetType == "funny" }, nil
+		case "full":
+			return func(c *scryfall.Card) bool { return c.FullArt }, nil
+		}
+	}
+
+	term := key + op + value
+	if key == "" {
+		term = value
+	}
+	return nil, fmt.Errorf("offline search does not support %q", term)
+}
+
+func colorTerm(op, value string) (cardFilter, error) {
+	want := map[string]bool{}
+	if value != "c" && value != "colorless" {
+		for _, r := range value {
+			if !strings.ContainsRune("wubrg", r) {
+				return nil, fmt.Errorf("invalid color %q: use letters from wubrg or c", value)
+			}
+			want[strings.ToUpper(string(r))] = true
+		}
+	}
+
+	return func(c *scryfall.Card) bool {
+		// Double-faced cards keep their colors on the faces.
+		colors := cardColors(c)
+		shared := 0
+		for _, color := range colors {
+			if want[color] {
+				shared++
+			}
+		}
+		switch op {
+		case "=":
+			return shared == len(want) && len(colors) == len(want)
+		case "<=":
+			return shared == len(colors)
+		case "<":
+			return shared == len(colors) && len(colors) < len(want)
+		case ">":
+			return shared == len(want) && len(colors) > len(want)
+		case "!=":
+			return shared != len(want) || len(colors) != len(want)
+		}
+		if len(want) == 0 {
+			return len(colors) == 0
+		}
+		return shared == len(want)
+	}, nil
+}
+
+func compareOp(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
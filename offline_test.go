@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+func TestLocalColorTerms(t *testing.T) {
+	// Scryfall leaves the top-level colors empty on transforming cards.
+	delver := scryfall.Card{
+		Name: "Delver of Secrets // Insectile Aberration",
+		CardFaces: []scryfall.CardFace{
+			{Name: "Delver of Secrets", Colors: []string{"U"}},
+			{Name: "Insectile Aberration", Colors: []string{"U"}},
+		},
+	}
+	bolt := scryfall.Card{Name: "Lightning Bolt", Colors: []string{"R"}}
+	ornithopter := scryfall.Card{Name: "Ornithopter", Colors: []string{}}
+
+	tests := []struct {
+		query string
+		card  scryfall.Card
+		want  bool
+	}{
+		{"c:u", delver, true},
+		{"c:c", delver, false},
+		{"c=u", delver, true},
+		{"c<=ur", delver, true},
+		{"c:r", delver, false},
+		{"c:r", bolt, true},
+		{"c:c", ornithopter, true},
+		{"c:u", ornithopter, false},
+	}
+	for _, tt := range tests {
+		filters, _, err := parseLocalQuery(tt.query)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got := matchesAll(&tt.card, filters); got != tt.want {
+			t.Errorf("%s on %s = %v, want %v", tt.query, tt.card.Name, got, tt.want)
+		}
+	}
+}
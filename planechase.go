@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+const minPlanarDeck = 10
+
+var planarDieFaces = []string{"planeswalk", "chaos", "blank", "blank", "blank", "blank"}
+
+type supplementalDeck struct {
+	name    string
+	query   string
+	advance string
+}
+
+// planeQuery finds planes. Scryfall's t: matches part of a word, so t:plane
+// alone would also find planeswalkers.
+const planeQuery = "t:plane -t:planeswalker"
+
+var planechaseDeck = supplementalDeck{
+	name:    "planar",
+	query:   "(" + planeQuery + ") or t:phenomenon",
+	advance: "planeswalk",
+}
+
+var archenemyDeck = supplementalDeck{
+	name:    "scheme",
+	query:   "t:scheme",
+	advance: "set a scheme in motion",
+}
+
+func runPlanechase(args []string) error {
+	fs := flag.NewFlagSet("planechase", flag.ExitOnError)
+	size := fs.Int("size", minPlanarDeck, "number of cards in the planar deck (0 for every plane)")
+	phenomena := fs.Bool("phenomena", true, "include phenomenon cards")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: planechase [flags] deal")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.Arg(0) != "deal" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	deck := planechaseDeck
+	if !*phenomena {
+		deck.query = planeQuery
+	}
+	return dealSupplemental(deck, *size, true)
+}
+
+func runArchenemy(args []string) error {
+	fs := flag.NewFlagSet("archenemy", flag.ExitOnError)
+	size := fs.Int("size", 20, "number of cards in the scheme deck (0 for every scheme)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: archenemy [flags] deal")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.Arg(0) != "deal" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return dealSupplemental(archenemyDeck, *size, false)
+}
+
+func dealSupplemental(deck supplementalDeck, size int, planarDie bool) error {
+	cards, err := fetchCards(deck.query, nil)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return fmt.Errorf("no %s cards found", deck.name)
+	}
+
+	rng := newRand()
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	if size > 0 && size < len(cards) {
+		cards = cards[:size]
+	}
+
+	fmt.Printf("Shuffled a %d-card %s deck (seed %d).\n", len(cards), deck.name, seed)
+	help := fmt.Sprintf("Enter: %s • q: quit", deck.advance)
+	if planarDie {
+		help = fmt.Sprintf("Enter: %s • r: roll the planar die • q: quit", deck.advance)
+	}
+
+	top := 0
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Println()
+		fmt.Println(formatCardPlain(&cards[top]))
+		fmt.Println(helpStyle.Render(help))
+
+		for {
+			fmt.Print("> ")
+			if !scanner.Scan() {
+				return scanner.Err()
+			}
+			cmd := strings.TrimSpace(strings.ToLower(scanner.Text()))
+			if cmd == "q" || cmd == "quit" {
+				return nil
+			}
+			if planarDie && (cmd == "r" || cmd == "roll") {
+				face := planarDieFaces[rng.IntN(len(planarDieFaces))]
+				fmt.Printf("Rolled %s\n", strings.ToUpper(face))
+				if face != "planeswalk" {
+					continue
+				}
+			}
+			break
+		}
+
+		// The revealed card goes to the bottom, so the deck cycles forever.
+		top = (top + 1) % len(cards)
+		if top == 0 {
+			fmt.Println(cardDetailStyle.Render(fmt.Sprintf("(back to the start of the %s deck)", deck.name)))
+		}
+	}
+}
+
+func formatCardPlain(card *scryfall.Card) string {
+	var b strings.Builder
+
+	for i, face := range card.Faces() {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(cardTitleStyle.Render(strings.TrimSpace(face.Name + " " + face.ManaCost)))
+		b.WriteString("\n")
+		b.WriteString(face.TypeLine)
+		b.WriteString("\n")
+		if face.OracleText != "" {
+			b.WriteString("\n")
+			b.WriteString(wrapText(face.OracleText, 70))
+			b.WriteString("\n")
+		}
+		if face.Power != "" && face.Toughness != "" {
+			b.WriteString(fmt.Sprintf("%s/%s\n", face.Power, face.Toughness))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
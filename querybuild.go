@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// queryParts holds the plain-language filters of the build command and the
+// search flags, each empty when unused.
+type queryParts struct {
+	Colors    string
+	Type      string
+	ManaValue string
+	Text      string
+	Format    string
+	Rarity    string
+}
+
+var (
+	manaValueRangePattern = regexp.MustCompile(`^(\d+)\s*-\s*(\d+)$`)
+	manaValuePattern      = regexp.MustCompile(`^(<=|>=|!=|<|>|=)?\s*(\d+)$`)
+)
+
+// compileQuery turns queryParts into Scryfall syntax. A mana value can be
+// "3", a comparison such as "<=3", or a range such as "2-4".
+func compileQuery(p queryParts) (string, error) {
+	var terms []string
+	if c := strings.ToLower(strings.TrimSpace(p.Colors)); c != "" {
+		if strings.Trim(c, "wubrgc") != "" {
+			return "", fmt.Errorf("invalid colors %q: use letters from wubrg, or c for colorless", p.Colors)
+		}
+		terms = append(terms, "c:"+c)
+	}
+	for _, t := range strings.Fields(strings.ToLower(p.Type)) {
+		terms = append(terms, "t:"+t)
+	}
+	if mv := strings.TrimSpace(p.ManaValue); mv != "" {
+		if m := manaValueRangePattern.FindStringSubmatch(mv); m != nil {
+			terms = append(terms, "cmc>="+m[1], "cmc<="+m[2])
+		} else if m := manaValuePattern.FindStringSubmatch(mv); m != nil {
+			op := m[1]
+			if op == "" {
+				op = "="
+			}
+			terms = append(terms, "cmc"+op+m[2])
+		} else {
+			return "", fmt.Errorf("invalid mana value %q: use a number, a comparison like <=3, or a range like 2-4", p.ManaValue)
+		}
+	}
+	if text := strings.TrimSpace(p.Text); text != "" {
+		terms = append(terms, fmt.Sprintf("o:%q", strings.ReplaceAll(text, `"`, "")))
+	}
+	if f := strings.ToLower(strings.TrimSpace(p.Format)); f != "" {
+		terms = append(terms, "f:"+f)
+	}
+	if r := strings.ToLower(strings.TrimSpace(p.Rarity)); r != "" {
+		if _, short := rarityNames[r]; !short && !knownRarity(r) {
+			return "", fmt.Errorf("invalid rarity %q: use common, uncommon, rare, or mythic", p.Rarity)
+		}
+		terms = append(terms, "r:"+r)
+	}
+	return strings.Join(terms, " "), nil
+}
+
+func knownRarity(r string) bool {
+	for _, name := range statsRarityOrder {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+func runBuild(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("usage: build (it asks for each filter in turn)")
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	ask := func(prompt string) string {
+		fmt.Printf("%s: ", prompt)
+		if !in.Scan() {
+			return ""
+		}
+		return strings.TrimSpace(in.Text())
+	}
+
+	fmt.Println("Answer each question, or press Enter to skip it.")
+	for {
+		var p queryParts
+		p.Colors = ask("Colors (letters from wubrg, c for colorless)")
+		p.Type = ask("Card type (e.g. creature, legendary artifact)")
+		p.ManaValue = ask("Mana value (e.g. 3, <=3, or 2-4)")
+		p.Text = ask("Rules text contains")
+		p.Format = ask("Legal in format (e.g. standard, commander)")
+		p.Rarity = ask("Rarity (common, uncommon, rare, mythic)")
+		if err := in.Err(); err != nil {
+			return fmt.Errorf("failed to read answers: %w", err)
+		}
+
+		query, err := compileQuery(p)
+		if err != nil {
+			fmt.Printf("%v; let's try again.\n\n", err)
+			continue
+		}
+		if query == "" {
+			return fmt.Errorf("no filters given")
+		}
+		fmt.Printf("\nScryfall query: %s\n\n", query)
+		return runSearch(query)
+	}
+}
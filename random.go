@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/render"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+// randomRedraws is how many draws per card random makes before giving up on
+// finding one that keepCards keeps.
+const randomRedraws = 10
+
+func runRandom(args []string) error {
+	fs := flag.NewFlagSet("random", flag.ExitOnError)
+	count := fs.Int("count", 1, "number of random cards to draw")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: random [flags] [query]")
+		fmt.Fprintln(fs.Output(), "Prints a random card, or one matching a Scryfall query such as \"is:commander\".")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *count < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	query := withGlobalFilters(strings.Join(fs.Args(), " "))
+
+	var cards []scryfall.Card
+	if offline {
+		// Draws with replacement, like repeated calls to Scryfall would.
+		matches, err := localSearch(query, extraTypePattern.MatchString(query))
+		if err != nil {
+			return err
+		}
+		matches = keepCards(query, matches)
+		if len(matches) == 0 {
+			return errNoCards
+		}
+		r := newRand()
+		for range *count {
+			cards = append(cards, matches[r.IntN(len(matches))])
+		}
+	} else {
+		// Scryfall can draw printings that keepCards leaves out, such as
+		// oversized cards, so draw again for those, up to a limit in case
+		// the query matches little else.
+		for tries := 0; len(cards) < *count; tries++ {
+			if tries == *count*randomRedraws {
+				if len(cards) == 0 {
+					return errNoCards
+				}
+				break
+			}
+			card, err := api.Random(query)
+			if err != nil {
+				return err
+			}
+			cards = append(cards, keepCards(query, []scryfall.Card{*card})...)
+		}
+	}
+	return render.Write(os.Stdout, cards, outputFormat)
+}
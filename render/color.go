@@ -0,0 +1,92 @@
+package render
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Color turns on ANSI colors and mana symbols in the compact, plain, and
+// prints formats. Callers set it only when writing to a terminal; the table format
+// stays plain so its columns line up.
+var Color bool
+
+var manaColors = map[string]lipgloss.Color{
+	"W": "230",
+	"U": "39",
+	"B": "141",
+	"R": "203",
+	"G": "78",
+	"C": "250",
+}
+
+var rarityColors = map[string]lipgloss.Color{
+	"common":   "250",
+	"uncommon": "152",
+	"rare":     "220",
+	"mythic":   "208",
+	"special":  "135",
+	"bonus":    "135",
+}
+
+var manaSymbolPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// Mana draws a mana cost such as "{2}{U}{R}" with a colored dot for each
+// colored symbol and a circled number for generic mana. Hybrid and Phyrexian
+// symbols keep their letters, colored by their first color.
+func Mana(cost string) string {
+	return manaSymbolPattern.ReplaceAllStringFunc(cost, func(sym string) string {
+		s := sym[1 : len(sym)-1]
+		if n, err := strconv.Atoi(s); err == nil && n <= 20 {
+			if n == 0 {
+				return "⓪"
+			}
+			return string(rune('①' + n - 1))
+		}
+		switch s {
+		case "X", "Y", "Z":
+			return string('Ⓧ' + rune(s[0]-'X'))
+		case "C":
+			return lipgloss.NewStyle().Foreground(manaColors["C"]).Render("◇")
+		case "S":
+			return "❄"
+		}
+		if color, ok := manaColors[s]; ok {
+			return lipgloss.NewStyle().Foreground(color).Render("●")
+		}
+		for _, r := range s {
+			if color, ok := manaColors[string(r)]; ok && r != 'C' {
+				return lipgloss.NewStyle().Foreground(color).Render(s)
+			}
+		}
+		return sym
+	})
+}
+
+// Rarity colors text the way set symbols show a card's rarity.
+func Rarity(rarity, text string) string {
+	color, ok := rarityColors[rarity]
+	if !ok {
+		return text
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(text)
+}
+
+// ColorIdentity draws a card's colors or color identity as colored dots in
+// WUBRG order, or a diamond for colorless.
+func ColorIdentity(colors []string) string {
+	var b strings.Builder
+	for _, c := range []string{"W", "U", "B", "R", "G"} {
+		for _, have := range colors {
+			if have == c {
+				b.WriteString(lipgloss.NewStyle().Foreground(manaColors[c]).Render("●"))
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return lipgloss.NewStyle().Foreground(manaColors["C"]).Render("◇")
+	}
+	return b.String()
+}
@@ -0,0 +1,236 @@
+// Package render writes search results in the output formats scripts rely
+// on. Every format has a golden file under testdata, so changes to the
+// output show up as test failures.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudsmyth/tradingcardsearch/decks"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+// Formats lists the formats Write accepts. "text" is also accepted as the
+// original name of compact.
+var Formats = []string{"compact", "plain", "table", "markdown", "json", "csv", "prints", "arena", "mtgo", "moxfield"}
+
+func Valid(format string) bool {
+	if format == "text" {
+		return true
+	}
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+func Write(w io.Writer, cards []scryfall.Card, format string) error {
+	switch format {
+	case "compact", "text":
+		return compact(w, cards)
+	case "plain":
+		return plain(w, cards)
+	case "table":
+		return table(w, cards)
+	case "markdown":
+		return markdown(w, cards)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cards)
+	case "csv":
+		return csvRows(w, cards)
+	case "prints":
+		return prints(w, cards)
+	case "arena":
+		return decks.WriteArena(w, deckCards(cards))
+	case "mtgo":
+		return decks.WriteMTGO(w, deckCards(cards))
+	case "moxfield":
+		return decks.WriteMoxfield(w, deckCards(cards))
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// frontFaceLayouts are the layouts deck builders know by the front face's
+// name alone. Split cards keep both halves.
+var frontFaceLayouts = map[string]bool{"transform": true, "modal_dfc": true, "adventure": true, "flip": true}
+
+// deckCards makes a decklist of one copy of each card, for importing
+// results into deck builders.
+func deckCards(cards []scryfall.Card) []decks.Card {
+	list := make([]decks.Card, len(cards))
+	for i, card := range cards {
+		name := card.Name
+		if frontFaceLayouts[card.Layout] {
+			name, _, _ = strings.Cut(name, " // ")
+		}
+		list[i] = decks.Card{Count: 1, Name: name, Set: card.Set, Number: card.CollectorNumber, Section: decks.Main}
+	}
+	return list
+}
+
+// compact writes a tab-separated line per card for grep and cut.
+func compact(w io.Writer, cards []scryfall.Card) error {
+	for _, card := range cards {
+		cost, set := card.ManaCost, strings.ToUpper(card.Set)
+		if Color {
+			cost, set = Mana(cost), Rarity(card.Rarity, set)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", card.Name, cost, card.TypeLine, set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plain writes each card as a block of text, separated by blank lines.
+func plain(w io.Writer, cards []scryfall.Card) error {
+	var b strings.Builder
+	for i, card := range cards {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for j, face := range card.Faces() {
+			if j > 0 {
+				b.WriteString("//\n")
+			}
+			cost := face.ManaCost
+			if Color {
+				cost = Mana(cost)
+			}
+			b.WriteString(strings.TrimSpace(face.Name + " " + cost))
+			b.WriteString("\n")
+			b.WriteString(face.TypeLine)
+			b.WriteString("\n")
+			if face.OracleText != "" {
+				b.WriteString(face.OracleText)
+				b.WriteString("\n")
+			}
+			if face.Power != "" && face.Toughness != "" {
+				fmt.Fprintf(&b, "%s/%s\n", face.Power, face.Toughness)
+			}
+		}
+		rarity := card.Rarity
+		if Color {
+			rarity = Rarity(rarity, rarity)
+		}
+		fmt.Fprintf(&b, "%s #%s · %s · %s\n", strings.ToUpper(card.Set), card.CollectorNumber, rarity, price(card))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func table(w io.Writer, cards []scryfall.Card) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Name\tCost\tType\tSet\tRarity\tPrice")
+	for _, card := range cards {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", card.Name, card.ManaCost, card.TypeLine, strings.ToUpper(card.Set), card.Rarity, price(card))
+	}
+	return tw.Flush()
+}
+
+func markdown(w io.Writer, cards []scryfall.Card) error {
+	var b strings.Builder
+	b.WriteString("| Name | Cost | Type | Set | Rarity | Price |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, card := range cards {
+		name := markdownCell(card.Name)
+		if card.ScryfallURI != "" {
+			name = fmt.Sprintf("[%s](%s)", name, card.ScryfallURI)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+			name, markdownCell(card.ManaCost), markdownCell(card.TypeLine), strings.ToUpper(card.Set), card.Rarity, price(card))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", "<br>").Replace(s)
+}
+
+func csvRows(w io.Writer, cards []scryfall.Card) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "mana_cost", "type_line", "set", "collector_number", "rarity", "usd", "usd_foil", "eur", "tix", "oracle_text"})
+	for _, card := range cards {
+		cw.Write([]string{
+			card.Name,
+			card.ManaCost,
+			card.TypeLine,
+			strings.ToUpper(card.Set),
+			card.CollectorNumber,
+			card.Rarity,
+			card.Prices.USD,
+			card.Prices.USDFoil,
+			card.Prices.EUR,
+			card.Prices.TIX,
+			card.Text(),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// prints groups printings under their card, in the order each card first
+// appears, with a line per printing for comparing sets and prices.
+func prints(w io.Writer, cards []scryfall.Card) error {
+	var order []string
+	groups := map[string][]scryfall.Card{}
+	for _, card := range cards {
+		key := card.OracleID
+		if key == "" {
+			key = card.Name
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], card)
+	}
+
+	var b strings.Builder
+	for i, key := range order {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		first := groups[key][0]
+		cost := first.ManaCost
+		if Color {
+			cost = Mana(cost)
+		}
+		b.WriteString(strings.TrimSpace(first.Name + " " + cost))
+		b.WriteString("\n")
+		for _, card := range groups[key] {
+			rarity := card.Rarity
+			if Color {
+				rarity = Rarity(rarity, rarity)
+			}
+			fmt.Fprintf(&b, "  %s #%s", strings.ToUpper(card.Set), card.CollectorNumber)
+			if card.SetName != "" {
+				fmt.Fprintf(&b, " · %s", card.SetName)
+			}
+			fmt.Fprintf(&b, " · %s · %s\n", rarity, price(card))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func price(card scryfall.Card) string {
+	switch {
+	case card.Prices.USD != "":
+		return "$" + card.Prices.USD
+	case card.Prices.USDFoil != "":
+		return "$" + card.Prices.USDFoil + " foil"
+	case card.Prices.EUR != "":
+		return "€" + card.Prices.EUR
+	}
+	return "-"
+}
@@ -0,0 +1,110 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func loadCards(t *testing.T) []scryfall.Card {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "cards.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cards []scryfall.Card
+	if err := json.Unmarshal(data, &cards); err != nil {
+		t.Fatal(err)
+	}
+	return cards
+}
+
+func TestWriteGolden(t *testing.T) {
+	cards := loadCards(t)
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, cards, format); err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", format+".golden")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("%s output changed; run go test ./render -update if intended\n--- got\n%s\n--- want\n%s", format, buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestWriteTextIsCompact(t *testing.T) {
+	cards := loadCards(t)
+	var text, compact bytes.Buffer
+	if err := Write(&text, cards, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(&compact, cards, "compact"); err != nil {
+		t.Fatal(err)
+	}
+	if text.String() != compact.String() {
+		t.Errorf("text and compact differ:\n%s\n%s", text.String(), compact.String())
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, nil, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if Valid("xml") {
+		t.Error("xml should not be valid")
+	}
+}
+
+func TestMana(t *testing.T) {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	for cost, want := range map[string]string{
+		"{2}{U}{R}": "②●●",
+		"{X}{X}{G}": "ⓍⓍ●",
+		"{0}":       "⓪",
+		"{10}{C}":   "⑩◇",
+		"{W/P}{S}":  "W/P❄",
+		"":          "",
+	} {
+		if got := Mana(cost); got != want {
+			t.Errorf("Mana(%q) = %q, want %q", cost, got, want)
+		}
+	}
+}
+
+func TestPrintsGroupsPrintings(t *testing.T) {
+	cards := []scryfall.Card{
+		{OracleID: "bolt", Name: "Lightning Bolt", ManaCost: "{R}", Set: "m10", CollectorNumber: "146", Rarity: "common", Prices: scryfall.Prices{USD: "2.15"}},
+		{OracleID: "goyf", Name: "Tarmogoyf", ManaCost: "{1}{G}", Set: "mma", CollectorNumber: "166", Rarity: "mythic"},
+		{OracleID: "bolt", Name: "Lightning Bolt", ManaCost: "{R}", Set: "2xm", CollectorNumber: "129", Rarity: "uncommon", Prices: scryfall.Prices{USD: "0.99"}},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, cards, "prints"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Lightning Bolt {R}\n  M10 #146 · common · $2.15\n  2XM #129 · uncommon · $0.99\n\nTarmogoyf {1}{G}\n  MMA #166 · mythic · -\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
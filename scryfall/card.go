@@ -0,0 +1,165 @@
+package scryfall
+
+import (
+	"fmt"
+	"strings"
+)
+
+type List struct {
+	Object     string `json:"object"`
+	TotalCards int    `json:"total_cards"`
+	HasMore    bool   `json:"has_more"`
+	NextPage   string `json:"next_page"`
+	Data       []Card `json:"data"`
+}
+
+type Card struct {
+	ID               string            `json:"id"`
+	OracleID         string            `json:"oracle_id"`
+	Name             string            `json:"name"`
+	ManaCost         string            `json:"mana_cost"`
+	CMC              float64           `json:"cmc"`
+	TypeLine         string            `json:"type_line"`
+	OracleText       string            `json:"oracle_text"`
+	FlavorText       string            `json:"flavor_text"`
+	Power            string            `json:"power"`
+	Toughness        string            `json:"toughness"`
+	Colors           []string          `json:"colors"`
+	ColorIdentity    []string          `json:"color_identity"`
+	Set              string            `json:"set"`
+	SetName          string            `json:"set_name"`
+	CollectorNumber  string            `json:"collector_number"`
+	Rarity           string            `json:"rarity"`
+	Artist           string            `json:"artist"`
+	FullArt          bool              `json:"full_art"`
+	ReleasedAt       string            `json:"released_at"`
+	EDHRecRank       int               `json:"edhrec_rank"`
+	Layout           string            `json:"layout"`
+	SetType          string            `json:"set_type"`
+	Oversized        bool              `json:"oversized"`
+	BorderColor      string            `json:"border_color"`
+	SecurityStamp    string            `json:"security_stamp"`
+	AttractionLights []int             `json:"attraction_lights"`
+	ScryfallURI      string            `json:"scryfall_uri"`
+	RulingsURI       string            `json:"rulings_uri"`
+	ImageURIs        map[string]string `json:"image_uris"`
+	Legalities       map[string]string `json:"legalities"`
+	Prices           Prices            `json:"prices"`
+	PurchaseURIs     map[string]string `json:"purchase_uris"`
+	CardFaces        []CardFace        `json:"card_faces"`
+}
+
+// CardFace is one face of a double-faced, split, flip, or adventure card.
+// Double-faced cards keep their text and images only on the faces.
+type CardFace struct {
+	Name       string            `json:"name"`
+	ManaCost   string            `json:"mana_cost"`
+	TypeLine   string            `json:"type_line"`
+	OracleText string            `json:"oracle_text"`
+	FlavorText string            `json:"flavor_text"`
+	Power      string            `json:"power"`
+	Toughness  string            `json:"toughness"`
+	Colors     []string          `json:"colors"`
+	ImageURIs  map[string]string `json:"image_uris"`
+}
+
+// Faces returns the card's faces, or the card itself as a single face.
+func (c *Card) Faces() []CardFace {
+	if len(c.CardFaces) > 0 {
+		return c.CardFaces
+	}
+	return []CardFace{{
+		Name:       c.Name,
+		ManaCost:   c.ManaCost,
+		TypeLine:   c.TypeLine,
+		OracleText: c.OracleText,
+		FlavorText: c.FlavorText,
+		Power:      c.Power,
+		Toughness:  c.Toughness,
+		Colors:     c.Colors,
+		ImageURIs:  c.ImageURIs,
+	}}
+}
+
+// Text returns the rules text of every face, separated by "//" lines.
+func (c *Card) Text() string {
+	if c.OracleText != "" || len(c.CardFaces) == 0 {
+		return c.OracleText
+	}
+	texts := make([]string, len(c.CardFaces))
+	for i, face := range c.CardFaces {
+		texts[i] = face.OracleText
+	}
+	return strings.Join(texts, "\n//\n")
+}
+
+// Image returns the image of the given size ("small", "normal", "art_crop",
+// ...), falling back to the front face for double-faced cards.
+func (c *Card) Image(size string) string {
+	if uri := c.ImageURIs[size]; uri != "" {
+		return uri
+	}
+	if len(c.CardFaces) > 0 {
+		return c.CardFaces[0].ImageURIs[size]
+	}
+	return ""
+}
+
+type Prices struct {
+	USD     string `json:"usd"`
+	USDFoil string `json:"usd_foil"`
+	EUR     string `json:"eur"`
+	TIX     string `json:"tix"`
+}
+
+type Set struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	SetType     string `json:"set_type"`
+	ReleasedAt  string `json:"released_at"`
+	CardCount   int    `json:"card_count"`
+	Digital     bool   `json:"digital"`
+	ScryfallURI string `json:"scryfall_uri"`
+}
+
+// BulkData describes one of the daily card exports Scryfall publishes.
+type BulkData struct {
+	Type        string `json:"type"`
+	UpdatedAt   string `json:"updated_at"`
+	DownloadURI string `json:"download_uri"`
+	Size        int64  `json:"size"`
+}
+
+// Identifier names one card for a collection lookup: by name, by name within
+// a set, or by set and collector number.
+type Identifier struct {
+	Name            string `json:"name,omitempty"`
+	Set             string `json:"set,omitempty"`
+	CollectorNumber string `json:"collector_number,omitempty"`
+}
+
+type collectionResponse struct {
+	NotFound []Identifier `json:"not_found"`
+	Data     []Card       `json:"data"`
+}
+
+type catalog struct {
+	Object string   `json:"object"`
+	Data   []string `json:"data"`
+}
+
+type setList struct {
+	Object string `json:"object"`
+	Data   []Set  `json:"data"`
+}
+
+// Error is the error object Scryfall returns with non-200 responses.
+type Error struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Details string `json:"details"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("API returned status %d: %s", e.Status, e.Details)
+}
@@ -0,0 +1,313 @@
+// Package scryfall is a small client for the Scryfall card API.
+package scryfall
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBaseURL   = "https://api.scryfall.com"
+	DefaultUserAgent = "mtg-go-search/1.0"
+	DefaultTimeout   = 30 * time.Second
+
+	// DefaultRateLimit is the minimum spacing between requests that
+	// Scryfall asks API clients to keep.
+	DefaultRateLimit = 100 * time.Millisecond
+
+	// MaxCollectionSize is the most identifiers one collection request takes.
+	MaxCollectionSize = 75
+)
+
+var ErrRateLimited = errors.New("rate limited by Scryfall API")
+
+type Client struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+	rateLimit  time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+type Option func(*Client)
+
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimRight(baseURL, "/") }
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.httpClient.Timeout = timeout }
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) { c.userAgent = userAgent }
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+func WithRateLimit(interval time.Duration) Option {
+	return func(c *Client) { c.rateLimit = interval }
+}
+
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		userAgent:  DefaultUserAgent,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		rateLimit:  DefaultRateLimit,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type SearchOptions struct {
+	Order         string
+	Dir           string
+	Unique        string
+	IncludeExtras bool
+}
+
+// Search runs a full-text search and returns the first page of results.
+// A query that matches nothing returns no cards and no error.
+func (c *Client) Search(query string, opts SearchOptions) (*List, error) {
+	params := url.Values{}
+	params.Add("q", query)
+	if opts.Order != "" {
+		params.Add("order", opts.Order)
+	}
+	if opts.Dir != "" {
+		params.Add("dir", opts.Dir)
+	}
+	if opts.Unique != "" {
+		params.Add("unique", opts.Unique)
+	}
+	if opts.IncludeExtras {
+		params.Add("include_extras", "true")
+	}
+
+	var list List
+	err := c.get("/cards/search", params, &list)
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return &List{Object: "list"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Next fetches the page after list, which must have HasMore set.
+func (c *Client) Next(list *List) (*List, error) {
+	if !list.HasMore || list.NextPage == "" {
+		return nil, fmt.Errorf("no more pages")
+	}
+
+	var next List
+	if err := c.getURL(list.NextPage, &next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// GetCardByName looks up a card by a fuzzy, partial, or misspelled name.
+func (c *Client) GetCardByName(name string) (*Card, error) {
+	params := url.Values{}
+	params.Add("fuzzy", name)
+
+	var card Card
+	if err := c.get("/cards/named", params, &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// Autocomplete returns up to 20 card names that start with or contain the
+// given text, for completing names as they are typed.
+func (c *Client) Autocomplete(text string) ([]string, error) {
+	params := url.Values{}
+	params.Add("q", text)
+
+	var names catalog
+	if err := c.get("/cards/autocomplete", params, &names); err != nil {
+		return nil, err
+	}
+	return names.Data, nil
+}
+
+// Random returns a random card, limited to cards matching query when it is
+// not empty.
+func (c *Client) Random(query string) (*Card, error) {
+	params := url.Values{}
+	if query != "" {
+		params.Add("q", query)
+	}
+
+	var card Card
+	if err := c.get("/cards/random", params, &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// GetCard looks up one printing by set code and collector number.
+func (c *Client) GetCard(set, number string) (*Card, error) {
+	path := fmt.Sprintf("/cards/%s/%s", url.PathEscape(strings.ToLower(set)), url.PathEscape(number))
+
+	var card Card
+	if err := c.get(path, nil, &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// Collection looks up many cards at once, batching identifiers to the 75 per
+// request that Scryfall allows. Identifiers that match nothing are returned
+// separately rather than as an error.
+func (c *Client) Collection(ids []Identifier) ([]Card, []Identifier, error) {
+	var cards []Card
+	var notFound []Identifier
+	for start := 0; start < len(ids); start += MaxCollectionSize {
+		end := min(start+MaxCollectionSize, len(ids))
+		payload := map[string][]Identifier{"identifiers": ids[start:end]}
+
+		var resp collectionResponse
+		if err := c.post("/cards/collection", payload, &resp); err != nil {
+			return nil, nil, err
+		}
+		cards = append(cards, resp.Data...)
+		notFound = append(notFound, resp.NotFound...)
+	}
+	return cards, notFound, nil
+}
+
+// BulkData returns the current download for a bulk export such as
+// "default-cards" or "oracle-cards".
+func (c *Client) BulkData(kind string) (*BulkData, error) {
+	var bulk BulkData
+	if err := c.get("/bulk-data/"+url.PathEscape(kind), nil, &bulk); err != nil {
+		return nil, err
+	}
+	return &bulk, nil
+}
+
+// Download streams a bulk export to w. Bulk files run to hundreds of
+// megabytes, so the client's timeout does not apply.
+func (c *Client) Download(bulk *BulkData, w io.Writer) error {
+	req, err := http.NewRequest(http.MethodGet, bulk.DownloadURI, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	c.wait()
+	resp, err := (&http.Client{Transport: c.httpClient.Transport}).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &Error{Status: resp.StatusCode, Details: "bulk download failed"}
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", bulk.Type, err)
+	}
+	return nil
+}
+
+func (c *Client) Sets() ([]Set, error) {
+	var list setList
+	if err := c.get("/sets", nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+func (c *Client) get(path string, params url.Values, out any) error {
+	reqURL := c.baseURL + path
+	if len(params) > 0 {
+		reqURL = fmt.Sprintf("%s?%s", reqURL, params.Encode())
+	}
+	return c.getURL(reqURL, out)
+}
+
+func (c *Client) getURL(reqURL string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	return c.do(req, out)
+}
+
+func (c *Client) post(path string, payload any, out any) error {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(encoded))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, out)
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	c.wait()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &Error{Status: resp.StatusCode}
+		if json.Unmarshal(body, apiErr) != nil || apiErr.Details == "" {
+			apiErr.Details = string(body)
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return nil
+}
+
+// wait blocks until at least rateLimit has passed since the previous request.
+func (c *Client) wait() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if delay := c.rateLimit - time.Since(c.last); delay > 0 {
+		time.Sleep(delay)
+	}
+	c.last = time.Now()
+}
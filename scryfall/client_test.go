@@ -0,0 +1,150 @@
+package scryfall
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(append([]Option{WithBaseURL(server.URL), WithRateLimit(0)}, opts...)...)
+}
+
+func TestSearchNotFoundIsEmpty(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"object":"error","status":404,"code":"not_found","details":"Your query didn't match any cards."}`)
+	})
+	list, err := c.Search("t:nothing", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 0 || list.HasMore {
+		t.Errorf("got %+v, want an empty list", list)
+	}
+}
+
+func TestRateLimited(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	if _, err := c.GetCardByName("Lightning Bolt"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("got %v, want ErrRateLimited", err)
+	}
+}
+
+func TestErrorBody(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"object":"error","status":400,"code":"bad_request","details":"All of your terms were ignored."}`)
+	})
+	_, err := c.Search("foo:bar", SearchOptions{})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *Error", err)
+	}
+	want := Error{Status: http.StatusBadRequest, Code: "bad_request", Details: "All of your terms were ignored."}
+	if *apiErr != want {
+		t.Errorf("got %+v, want %+v", *apiErr, want)
+	}
+}
+
+func TestErrorBodyNotJSON(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, "upstream down")
+	})
+	_, err := c.GetCard("m10", "146")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusBadGateway || apiErr.Details != "upstream down" {
+		t.Errorf("got %v, want a 502 with the body as details", err)
+	}
+}
+
+func TestCollectionBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batches []int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/cards/collection" {
+			t.Errorf("got %s %s, want POST /cards/collection", r.Method, r.URL.Path)
+		}
+		var req struct {
+			Identifiers []Identifier `json:"identifiers"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		batches = append(batches, len(req.Identifiers))
+		mu.Unlock()
+
+		// The last identifier of each batch is unknown.
+		var resp collectionResponse
+		for _, id := range req.Identifiers[:len(req.Identifiers)-1] {
+			resp.Data = append(resp.Data, Card{Name: id.Name})
+		}
+		resp.NotFound = req.Identifiers[len(req.Identifiers)-1:]
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	ids := make([]Identifier, 160)
+	for i := range ids {
+		ids[i] = Identifier{Name: fmt.Sprintf("card %d", i)}
+	}
+	cards, notFound, err := c.Collection(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(batches) != "[75 75 10]" {
+		t.Errorf("got batches of %v, want [75 75 10]", batches)
+	}
+	if len(cards) != 157 || len(notFound) != 3 {
+		t.Errorf("got %d cards and %d not found, want 157 and 3", len(cards), len(notFound))
+	}
+	if cards[0].Name != "card 0" || notFound[2].Name != "card 159" {
+		t.Errorf("results out of order: first card %q, last not found %q", cards[0].Name, notFound[2].Name)
+	}
+}
+
+func TestRateLimitSpacesRequests(t *testing.T) {
+	const interval = 50 * time.Millisecond
+	var mu sync.Mutex
+	var times []time.Time
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		fmt.Fprint(w, `{"object":"card","name":"Lightning Bolt"}`)
+	}, WithRateLimit(interval))
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetCardByName("bolt"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(times) != 4 {
+		t.Fatalf("got %d requests, want 4", len(times))
+	}
+	for i := 1; i < len(times); i++ {
+		// The server sees requests a little after the client sends them, so
+		// allow some slack.
+		if gap := times[i].Sub(times[i-1]); gap < interval-10*time.Millisecond {
+			t.Errorf("request %d came %v after the one before, want at least %v", i, gap, interval)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/render"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+var errNoCards = errors.New("no cards found")
+
+var outputFormat = "compact"
+
+// stdinIsTerminal reports whether the search can be read interactively, as
+// opposed to a query piped or redirected in by a script.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return true
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdoutIsTerminal reports whether results are shown to a person rather
+// than piped to another program, which should not get ANSI colors.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func readQuery(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read query: %w", err)
+	}
+	return strings.Join(strings.Fields(string(data)), " "), nil
+}
+
+// runSearch runs one search for scripts and prints the results in
+// outputFormat. The default compact output is a tab-separated line per card,
+// so it can be piped to tools like grep and cut.
+func runSearch(query string) error {
+	input, order, err := splitSortOption(query)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		order = defaultSort
+	}
+
+	cards, err := fetchCards(input, order)
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return errNoCards
+	}
+
+	return render.Write(os.Stdout, cards, outputFormat)
+}
+
+// runURL runs the search behind a scryfall.com search URL, keeping its
+// order, direction, and unique settings.
+func runURL(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: url \"https://scryfall.com/search?q=...\"")
+	}
+	u, err := url.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if host := strings.TrimPrefix(u.Hostname(), "www."); host != "scryfall.com" || strings.TrimSuffix(u.Path, "/") != "/search" {
+		return fmt.Errorf("not a Scryfall search URL: %s", args[0])
+	}
+
+	params := u.Query()
+	query := params.Get("q")
+	if query == "" {
+		return fmt.Errorf("the URL has no q parameter")
+	}
+
+	page, err := api.Search(withGlobalFilters(query), scryfall.SearchOptions{
+		Order:         params.Get("order"),
+		Dir:           params.Get("dir"),
+		Unique:        params.Get("unique"),
+		IncludeExtras: params.Get("include_extras") == "true" || extraTypePattern.MatchString(query),
+	})
+	var cards []scryfall.Card
+	for err == nil {
+		cards = append(cards, keepCards(query, page.Data)...)
+		if !fetchAll || !page.HasMore {
+			break
+		}
+		page, err = api.Next(page)
+	}
+	if err != nil {
+		return err
+	}
+	if len(cards) == 0 {
+		return errNoCards
+	}
+	return render.Write(os.Stdout, cards, outputFormat)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cloudsmyth/tradingcardsearch/render"
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+func runSets(args []string) error {
+	fs := flag.NewFlagSet("sets", flag.ExitOnError)
+	year := fs.Int("year", 0, "only list sets released in this year")
+	types := fs.String("type", "", "only list these comma-separated set types, e.g. expansion,core,commander")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sets [flags]")
+		fmt.Fprintln(fs.Output(), "Lists Magic sets, newest first. Use the set command to list a set's cards.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	wantType := map[string]bool{}
+	for _, t := range strings.Split(strings.ToLower(*types), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			wantType[t] = true
+		}
+	}
+
+	sets, err := api.Sets()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Code\tName\tReleased\tCards\tType")
+	for _, s := range sets {
+		if *year != 0 && !strings.HasPrefix(s.ReleasedAt, strconv.Itoa(*year)+"-") {
+			continue
+		}
+		if len(wantType) > 0 && !wantType[s.SetType] {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", strings.ToUpper(s.Code), s.Name, s.ReleasedAt, s.CardCount, strings.ReplaceAll(s.SetType, "_", " "))
+	}
+	return tw.Flush()
+}
+
+// runSet lists every printing in a set in collector number order, in any of
+// the one-shot output formats.
+func runSet(args []string) error {
+	fs := flag.NewFlagSet("set", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: set <code>")
+		fmt.Fprintln(fs.Output(), "Lists the cards in a set, such as \"set mh3\".")
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	code := strings.ToLower(fs.Arg(0))
+
+	var cards []scryfall.Card
+	if offline {
+		path, err := bulkPath()
+		if err != nil {
+			return err
+		}
+		err = scanBulk(path, func(card scryfall.Card) error {
+			if card.Set == code {
+				cards = append(cards, card)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		page, err := api.Search("e:"+code, scryfall.SearchOptions{Order: "set", Unique: "prints", IncludeExtras: true})
+		for err == nil {
+			cards = append(cards, page.Data...)
+			if !page.HasMore {
+				break
+			}
+			page, err = api.Next(page)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(cards) == 0 {
+		return fmt.Errorf("no cards found in set %q; run the sets command for set codes", code)
+	}
+
+	sortCards(cards, []sortKey{{field: "number"}})
+	return render.Write(os.Stdout, cards, outputFormat)
+}
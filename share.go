@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const scryfallSearchPage = "https://scryfall.com/search"
+
+// scryfallSearchURL builds the scryfall.com page for a search, leaving out
+// parameters that match the site's defaults so the link stays short.
+func scryfallSearchURL(query string, order []sortKey) string {
+	params := url.Values{}
+	params.Set("q", withGlobalFilters(query))
+	if field, dir := scryfallOrder(order); field != "name" || dir == "desc" {
+		params.Set("order", field)
+		if dir != "" {
+			params.Set("dir", dir)
+		}
+	}
+	return scryfallSearchPage + "?" + params.Encode()
+}
+
+func runShare(args []string) error {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	copyURL := fs.Bool("copy", false, "also copy the URL to the clipboard (needs a terminal that supports OSC 52)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: share [flags] <query> [--sort keys]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	query, order, err := splitSortOption(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+	if query == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if order == nil {
+		order = defaultSort
+	}
+
+	link := scryfallSearchURL(query, order)
+	fmt.Println(link)
+	if *copyURL {
+		fmt.Fprint(os.Stderr, osc52(link))
+	}
+	return nil
+}
+
+// osc52 asks the terminal to put text on the system clipboard, which works
+// over SSH without a clipboard tool installed.
+func osc52(text string) string {
+	return "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+type sortField struct {
+	scryfall string
+	compare  func(a, b *scryfall.Card) int
+	missing  func(c *scryfall.Card) bool
+}
+
+var sortFields = map[string]sortField{
+	"name": {scryfall: "name", compare: func(a, b *scryfall.Card) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	}},
+	"cmc": {scryfall: "cmc", compare: func(a, b *scryfall.Card) int {
+		return compareFloat(a.CMC, b.CMC)
+	}},
+	"price": {scryfall: "usd", compare: comparePrice(func(c *scryfall.Card) string { return c.Prices.USD }),
+		missing: func(c *scryfall.Card) bool { return c.Prices.USD == "" }},
+	"eur": {scryfall: "eur", compare: comparePrice(func(c *scryfall.Card) string { return c.Prices.EUR }),
+		missing: func(c *scryfall.Card) bool { return c.Prices.EUR == "" }},
+	"tix": {scryfall: "tix", compare: comparePrice(func(c *scryfall.Card) string { return c.Prices.TIX }),
+		missing: func(c *scryfall.Card) bool { return c.Prices.TIX == "" }},
+	"set": {scryfall: "set", compare: func(a, b *scryfall.Card) int {
+		return strings.Compare(a.Set, b.Set)
+	}},
+	"number": {compare: func(a, b *scryfall.Card) int {
+		return compareCollectorNumber(a.CollectorNumber, b.CollectorNumber)
+	}},
+	"rarity": {scryfall: "rarity", compare: func(a, b *scryfall.Card) int {
+		return rarityRank[a.Rarity] - rarityRank[b.Rarity]
+	}},
+	"color": {scryfall: "color", compare: func(a, b *scryfall.Card) int {
+		return colorRank(cardColors(a)) - colorRank(cardColors(b))
+	}},
+	"power": {scryfall: "power", compare: func(a, b *scryfall.Card) int {
+		return compareFloat(statValue(a.Power), statValue(b.Power))
+	}, missing: func(c *scryfall.Card) bool { return c.Power == "" }},
+	"toughness": {scryfall: "toughness", compare: func(a, b *scryfall.Card) int {
+		return compareFloat(statValue(a.Toughness), statValue(b.Toughness))
+	}, missing: func(c *scryfall.Card) bool { return c.Toughness == "" }},
+	"released": {scryfall: "released", compare: func(a, b *scryfall.Card) int {
+		return strings.Compare(a.ReleasedAt, b.ReleasedAt)
+	}},
+	"edhrec": {scryfall: "edhrec", compare: func(a, b *scryfall.Card) int {
+		return a.EDHRecRank - b.EDHRecRank
+	}, missing: func(c *scryfall.Card) bool { return c.EDHRecRank == 0 }},
+}
+
+var sortAliases = map[string]string{
+	"usd":  "price",
+	"mv":   "cmc",
+	"pow":  "power",
+	"tou":  "toughness",
+	"cn":   "number",
+	"date": "released",
+}
+
+var rarityRank = map[string]int{
+	"common":   1,
+	"uncommon": 2,
+	"rare":     3,
+	"special":  4,
+	"mythic":   5,
+	"bonus":    6,
+}
+
+var defaultSort []sortKey
+
+func parseSortKeys(spec string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+
+		field, dir, _ := strings.Cut(part, ":")
+		if alias, ok := sortAliases[field]; ok {
+			field = alias
+		}
+		if _, ok := sortFields[field]; !ok {
+			return nil, fmt.Errorf("unknown sort key %q", field)
+		}
+
+		key := sortKey{field: field}
+		switch dir {
+		case "", "asc":
+		case "desc":
+			key.desc = true
+		default:
+			return nil, fmt.Errorf("unknown sort direction %q for %s (use asc or desc)", dir, field)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// withOrder puts the -order key, in the -dir direction, ahead of the -sort
+// keys. Without -order, -dir sets the direction of the first key, or of the
+// name order Scryfall uses by default.
+func withOrder(keys []sortKey, field, dir string) ([]sortKey, error) {
+	if field == "" {
+		field = "name"
+		if len(keys) > 0 {
+			field, keys = keys[0].field, keys[1:]
+		}
+	}
+	if dir != "" {
+		field += ":" + dir
+	}
+	primary, err := parseSortKeys(field)
+	if err != nil {
+		return nil, err
+	}
+	if len(primary) != 1 {
+		return nil, fmt.Errorf("-order takes one key, not %q", field)
+	}
+	return append(primary, keys...), nil
+}
+
+// splitSortOption pulls a "--sort spec" option out of a search string. The
+// returned keys are nil when the string has no sort option.
+func splitSortOption(input string) (string, []sortKey, error) {
+	fields := strings.Fields(input)
+	for i, f := range fields {
+		spec, ok := "", false
+		switch {
+		case f == "--sort" && i+1 < len(fields):
+			spec, ok = fields[i+1], true
+			fields = append(fields[:i], fields[i+2:]...)
+		case strings.HasPrefix(f, "--sort="):
+			spec, ok = strings.TrimPrefix(f, "--sort="), true
+			fields = append(fields[:i], fields[i+1:]...)
+		}
+		if ok {
+			keys, err := parseSortKeys(spec)
+			return strings.Join(fields, " "), keys, err
+		}
+	}
+	return input, nil, nil
+}
+
+func sortCards(cards []scryfall.Card, keys []sortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		a, b := &cards[i], &cards[j]
+		for _, key := range keys {
+			field := sortFields[key.field]
+			if field.missing != nil {
+				ma, mb := field.missing(a), field.missing(b)
+				if ma != mb {
+					return mb
+				}
+				if ma {
+					continue
+				}
+			}
+			c := field.compare(a, b)
+			if key.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// scryfallOrder returns the order and dir parameters for the primary sort
+// key, so the API pages results in roughly the order they are shown.
+func scryfallOrder(keys []sortKey) (string, string) {
+	if len(keys) == 0 || sortFields[keys[0].field].scryfall == "" {
+		return "name", ""
+	}
+	dir := "asc"
+	if keys[0].desc {
+		dir = "desc"
+	}
+	return sortFields[keys[0].field].scryfall, dir
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func comparePrice(price func(c *scryfall.Card) string) func(a, b *scryfall.Card) int {
+	return func(a, b *scryfall.Card) int {
+		pa, _ := strconv.ParseFloat(price(a), 64)
+		pb, _ := strconv.ParseFloat(price(b), 64)
+		return compareFloat(pa, pb)
+	}
+}
+
+func compareCollectorNumber(a, b string) int {
+	na, ra := leadingNumber(a)
+	nb, rb := leadingNumber(b)
+	if na != nb {
+		return na - nb
+	}
+	return strings.Compare(ra, rb)
+}
+
+func leadingNumber(s string) (int, string) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n, s[i:]
+}
+
+func statValue(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimRight(s, "+*"), 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func colorRank(colors []string) int {
+	const order = "WUBRG"
+	rank := len(colors) * 100
+	for _, c := range colors {
+		if i := strings.Index(order, c); i >= 0 {
+			rank += 1 << i
+		}
+	}
+	return rank
+}
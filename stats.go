@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+const (
+	statsBarWidth = 30
+	statsMaxSets  = 10
+)
+
+var statsColorOrder = []string{"W", "U", "B", "R", "G", "Multicolor", "Colorless"}
+
+var statsColorNames = map[string]string{
+	"W": "White",
+	"U": "Blue",
+	"B": "Black",
+	"R": "Red",
+	"G": "Green",
+}
+
+var statsRarityOrder = []string{"common", "uncommon", "rare", "mythic", "special", "bonus"}
+
+var priceBuckets = []struct {
+	label string
+	max   float64
+}{
+	{"under $1", 1},
+	{"$1–5", 5},
+	{"$5–20", 20},
+	{"$20–50", 50},
+	{"$50+", -1},
+}
+
+type countRow struct {
+	label string
+	count int
+}
+
+func renderStats(cards []scryfall.Card) string {
+	var b strings.Builder
+
+	b.WriteString(cardTitleStyle.Render(fmt.Sprintf("Statistics for %d cards", len(cards))))
+	b.WriteString("\n\n")
+
+	colors := map[string]int{}
+	rarities := map[string]int{}
+	sets := map[string]int{}
+	var cmcs, prices []float64
+
+	for _, card := range cards {
+		switch c := cardColors(&card); len(c) {
+		case 0:
+			colors["Colorless"]++
+		case 1:
+			colors[c[0]]++
+		default:
+			colors["Multicolor"]++
+		}
+		rarities[card.Rarity]++
+		sets[card.SetName]++
+		if !strings.Contains(card.TypeLine, "Land") {
+			cmcs = append(cmcs, card.CMC)
+		}
+		if p, err := strconv.ParseFloat(card.Prices.USD, 64); err == nil {
+			prices = append(prices, p)
+		}
+	}
+
+	var rows []countRow
+	for _, c := range statsColorOrder {
+		if n := colors[c]; n > 0 {
+			label := c
+			if name, ok := statsColorNames[c]; ok {
+				label = name
+			}
+			rows = append(rows, countRow{label, n})
+		}
+	}
+	writeCountSection(&b, "Colors", rows, len(cards))
+
+	rows = nil
+	for _, r := range statsRarityOrder {
+		if n := rarities[r]; n > 0 {
+			rows = append(rows, countRow{r, n})
+		}
+	}
+	writeCountSection(&b, "Rarity", rows, len(cards))
+
+	rows = nil
+	for name, n := range sets {
+		rows = append(rows, countRow{name, n})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].count != rows[j].count {
+			return rows[i].count > rows[j].count
+		}
+		return rows[i].label < rows[j].label
+	})
+	if len(rows) > statsMaxSets {
+		others := 0
+		for _, r := range rows[statsMaxSets:] {
+			others += r.count
+		}
+		rows = append(rows[:statsMaxSets], countRow{fmt.Sprintf("%d other sets", len(sets)-statsMaxSets), others})
+	}
+	writeCountSection(&b, "Sets", rows, len(cards))
+
+	b.WriteString(cardDetailStyle.Render("Mana value (nonland):"))
+	b.WriteString("\n")
+	if len(cmcs) == 0 {
+		b.WriteString("  n/a\n\n")
+	} else {
+		fmt.Fprintf(&b, "  average %.2f • median %.1f\n\n", mean(cmcs), median(cmcs))
+	}
+
+	b.WriteString(cardDetailStyle.Render("Prices (USD):"))
+	b.WriteString("\n")
+	if len(prices) == 0 {
+		b.WriteString("  no price data\n")
+		return b.String()
+	}
+	sort.Float64s(prices)
+	total := 0.0
+	for _, p := range prices {
+		total += p
+	}
+	fmt.Fprintf(&b, "  %d priced • min $%.2f • median $%.2f • max $%.2f • total $%.2f\n",
+		len(prices), prices[0], median(prices), prices[len(prices)-1], total)
+
+	counts := make([]int, len(priceBuckets))
+	for _, p := range prices {
+		for i, bucket := range priceBuckets {
+			if bucket.max < 0 || p < bucket.max {
+				counts[i]++
+				break
+			}
+		}
+	}
+	rows = nil
+	for i, bucket := range priceBuckets {
+		rows = append(rows, countRow{bucket.label, counts[i]})
+	}
+	writeCountRows(&b, rows, len(prices))
+
+	return b.String()
+}
+
+func writeCountSection(b *strings.Builder, title string, rows []countRow, total int) {
+	b.WriteString(cardDetailStyle.Render(title + ":"))
+	b.WriteString("\n")
+	writeCountRows(b, rows, total)
+	b.WriteString("\n")
+}
+
+func writeCountRows(b *strings.Builder, rows []countRow, total int) {
+	width := 0
+	for _, r := range rows {
+		if n := utf8.RuneCountInString(r.label); n > width {
+			width = n
+		}
+	}
+	for _, r := range rows {
+		bar := 0
+		if total > 0 {
+			bar = r.count * statsBarWidth / total
+		}
+		if r.count > 0 && bar == 0 {
+			bar = 1
+		}
+		fmt.Fprintf(b, "  %-*s %4d  %s\n", width, r.label, r.count, strings.Repeat("█", bar))
+	}
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// cardColors falls back to the colors of every face, since Scryfall puts the
+// colors of transforming cards on their faces.
+func cardColors(c *scryfall.Card) []string {
+	if len(c.Colors) > 0 || len(c.CardFaces) == 0 {
+		return c.Colors
+	}
+	var colors []string
+	for _, color := range []string{"W", "U", "B", "R", "G"} {
+		for _, face := range c.CardFaces {
+			if slices.Contains(face.Colors, color) {
+				colors = append(colors, color)
+				break
+			}
+		}
+	}
+	return colors
+}
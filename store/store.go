@@ -0,0 +1,247 @@
+// Package store keeps cards in an embedded SQLite database with a full-text
+// index, so text searches over every printing avoid scanning the bulk JSON.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+	_ "modernc.org/sqlite"
+)
+
+// migrations[i] upgrades a store from schema version i to i+1, tracked in
+// SQLite's user_version. Add changes as new entries; never edit old ones,
+// since stores already past them will not run them again.
+var migrations = []string{
+	// The trigram tokenizer makes MATCH find substrings, case-insensitively,
+	// which is how Scryfall treats name, type, and oracle text terms.
+	`CREATE TABLE IF NOT EXISTS cards (
+		id   TEXT PRIMARY KEY,
+		data TEXT NOT NULL
+	);
+	CREATE VIRTUAL TABLE IF NOT EXISTS cards_fts USING fts5(
+		id UNINDEXED, name, type_line, oracle_text, artist,
+		tokenize = 'trigram'
+	);`,
+}
+
+// Version is the schema version this build reads and writes.
+var Version = len(migrations)
+
+// ErrOutdated is returned by OpenReadOnly for a store with an older schema,
+// which can only be upgraded by opening it for writing.
+var ErrOutdated = errors.New("store has an older schema")
+
+// busyTimeout is in milliseconds.
+const busyTimeout = "5000"
+
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens a store for writing, waiting up to busyTimeout for another
+// writer to finish rather than failing with "database is locked".
+func Open(path string) (*Store, error) {
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "_pragma=busy_timeout(" + busyTimeout + ")&_txlock=immediate"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	s := &Store{db: db}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to update schema in %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// migrate runs each migration in its own transaction, reading the version
+// inside it so that two processes opening the store at once do not both run
+// the same migration. A store written before versions were tracked gets its
+// version recorded.
+func (s *Store) migrate() error {
+	for {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		version, recorded, err := schemaVersion(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if version > Version {
+			tx.Rollback()
+			return fmt.Errorf("schema version %d is newer than this program supports (%d)", version, Version)
+		}
+		if version == Version && recorded == Version {
+			return tx.Rollback()
+		}
+		if version < Version {
+			if _, err := tx.Exec(migrations[version]); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %d: %w", version+1, err)
+			}
+			version++
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+}
+
+// Version returns the store's schema version. Stores written before
+// versions were tracked report 1 if they have its tables.
+func (s *Store) Version() (int, error) {
+	version, _, err := schemaVersion(s.db)
+	return version, err
+}
+
+// schemaVersion returns the store's schema version and the one recorded in
+// user_version, which is 0 for stores written before versions were tracked.
+func schemaVersion(q interface {
+	QueryRow(query string, args ...any) *sql.Row
+}) (version, recorded int, err error) {
+	if err := q.QueryRow("PRAGMA user_version").Scan(&recorded); err != nil {
+		return 0, 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	version = recorded
+	if version == 0 {
+		var n int
+		if err := q.QueryRow(`SELECT count(*) FROM sqlite_master WHERE name = 'cards_fts'`).Scan(&n); err != nil {
+			return 0, 0, fmt.Errorf("failed to read schema: %w", err)
+		}
+		if n > 0 {
+			version = 1
+		}
+	}
+	return version, recorded, nil
+}
+
+// OpenReadOnly opens a store for queries only. The file must not change
+// while it is open; replace it with a rename instead, as sync does. SQLite
+// then takes no locks, so any number of readers, in this process or others,
+// can query it at once, including on network file systems where its locking
+// is unreliable. The Store is safe for concurrent use and pools connections.
+func OpenReadOnly(path string) (*Store, error) {
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro&immutable=1"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	s := &Store{db: db}
+	version, err := s.Version()
+	if err == nil && version != Version {
+		err = ErrOutdated
+		if version > Version {
+			err = fmt.Errorf("schema version %d is newer than this program supports (%d)", version, Version)
+		}
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Check verifies the store's file and that every card has exactly one
+// search row, returning the number of cards.
+func (s *Store) Check() (int, error) {
+	var result string
+	if err := s.db.QueryRow("PRAGMA quick_check").Scan(&result); err != nil {
+		return 0, fmt.Errorf("failed to check store: %w", err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("store is damaged: %s", result)
+	}
+	var cards, rows, orphans int
+	err := s.db.QueryRow(`SELECT
+		(SELECT count(*) FROM cards),
+		(SELECT count(*) FROM cards_fts),
+		(SELECT count(*) FROM cards_fts WHERE id NOT IN (SELECT id FROM cards))`).Scan(&cards, &rows, &orphans)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cards: %w", err)
+	}
+	if rows != cards || orphans > 0 {
+		return cards, fmt.Errorf("store has %d cards but %d search rows, %d without a card", cards, rows, orphans)
+	}
+	return cards, nil
+}
+
+// Index adds cards to the store, replacing any already stored with the same
+// ID.
+func (s *Store) Index(cards []scryfall.Card) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, card := range cards {
+		data, err := json.Marshal(card)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", card.Name, err)
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO cards (id, data) VALUES (?, ?)`, card.ID, string(data)); err != nil {
+			return fmt.Errorf("failed to store %s: %w", card.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM cards_fts WHERE id = ?`, card.ID); err != nil {
+			return fmt.Errorf("failed to index %s: %w", card.Name, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO cards_fts (id, name, type_line, oracle_text, artist) VALUES (?, ?, ?, ?, ?)`,
+			card.ID, card.Name, card.TypeLine, card.Text(), card.Artist); err != nil {
+			return fmt.Errorf("failed to index %s: %w", card.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cards: %w", err)
+	}
+	return nil
+}
+
+// Query returns the cards matching an FTS5 expression, such as
+// `name:"goblin" AND oracle_text:"haste"`. Use Phrase to quote user input.
+func (s *Store) Query(q string) ([]scryfall.Card, error) {
+	rows, err := s.db.Query(`SELECT c.data FROM cards_fts f JOIN cards c ON c.id = f.id WHERE cards_fts MATCH ?`, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []scryfall.Card
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read card: %w", err)
+		}
+		var card scryfall.Card
+		if err := json.Unmarshal([]byte(data), &card); err != nil {
+			return nil, fmt.Errorf("failed to parse card: %w", err)
+		}
+		cards = append(cards, card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query cards: %w", err)
+	}
+	return cards, nil
+}
+
+// Phrase quotes text for use in a Query expression. The trigram index needs
+// at least three characters to match on.
+func Phrase(column, text string) string {
+	return fmt.Sprintf(`%s:"%s"`, column, strings.ReplaceAll(text, `"`, `""`))
+}
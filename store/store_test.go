@@ -0,0 +1,152 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/cloudsmyth/tradingcardsearch/scryfall"
+)
+
+// rawStore writes a store file directly, as an older or newer release
+// would have.
+func rawStore(t *testing.T, statements ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cards.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func userVersion(t *testing.T, s *Store) int {
+	t.Helper()
+	var v int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestOpenUpgradesUnversionedStore(t *testing.T) {
+	card, err := json.Marshal(scryfall.Card{ID: "1", Name: "Lightning Bolt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := rawStore(t, migrations[0],
+		`INSERT INTO cards (id, data) VALUES ('1', '`+string(card)+`')`,
+		`INSERT INTO cards_fts (id, name) VALUES ('1', 'Lightning Bolt')`)
+
+	ro, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("an unversioned store with the version 1 tables should open read-only: %v", err)
+	}
+	ro.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if v := userVersion(t, s); v != 1 {
+		t.Errorf("user_version = %d after Open, want 1", v)
+	}
+	if n, err := s.Check(); err != nil || n != 1 {
+		t.Errorf("Check() = %d, %v; want the card kept", n, err)
+	}
+}
+
+func TestOpenCreatesCurrentSchema(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "cards.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if v := userVersion(t, s); v != Version {
+		t.Errorf("user_version = %d, want %d", v, Version)
+	}
+}
+
+func TestOpenRefusesNewerSchema(t *testing.T) {
+	path := rawStore(t, fmt.Sprintf("PRAGMA user_version = %d", Version+1))
+
+	if s, err := Open(path); err == nil {
+		s.Close()
+		t.Error("Open succeeded on a store with a newer schema")
+	}
+	s, err := OpenReadOnly(path)
+	if err == nil {
+		s.Close()
+		t.Fatal("OpenReadOnly succeeded on a store with a newer schema")
+	}
+	if errors.Is(err, ErrOutdated) {
+		t.Errorf("got ErrOutdated for a newer schema: %v", err)
+	}
+}
+
+func TestOpenReadOnlyOutdated(t *testing.T) {
+	path := rawStore(t, `CREATE TABLE unrelated (x)`)
+	if _, err := OpenReadOnly(path); !errors.Is(err, ErrOutdated) {
+		t.Errorf("got %v, want ErrOutdated", err)
+	}
+}
+
+func TestConcurrentOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cards.db")
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := Open(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			s.Close()
+		}()
+	}
+	wg.Wait()
+
+	s, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if v := userVersion(t, s); v != Version {
+		t.Errorf("user_version = %d, want %d", v, Version)
+	}
+}
+
+func TestIndexAndQuery(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "cards.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	cards := []scryfall.Card{
+		{ID: "1", Name: "Goblin Guide", TypeLine: "Creature — Goblin Scout"},
+		{ID: "2", Name: "Lightning Bolt", TypeLine: "Instant"},
+	}
+	if err := s.Index(cards); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Query(Phrase("name", "GOBLIN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("got %+v, want Goblin Guide", got)
+	}
+}
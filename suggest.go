@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxSuggestions = 5
+
+// suggestionsMsg carries autocomplete results; fill means they complete the
+// search box rather than only being listed.
+type suggestionsMsg struct {
+	text  string
+	names []string
+	fill  bool
+	err   error
+}
+
+// suggestNames offers card names close to a search that found nothing: the
+// fuzzy match first, then autocomplete results. Searches using Scryfall
+// syntax get no suggestions, since their text is not a card name.
+func suggestNames(query string) []string {
+	name := strings.Trim(strings.TrimPrefix(query, "!"), `"`)
+	if offline || name == "" || strings.ContainsAny(name, ":<>=()") {
+		return nil
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	add := func(n string) {
+		if !seen[n] && len(names) < maxSuggestions {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	if card, err := api.GetCardByName(name); err == nil {
+		add(card.Name)
+	}
+	if more, err := api.Autocomplete(name); err == nil {
+		for _, n := range more {
+			add(n)
+		}
+	}
+	return names
+}
+
+func didYouMean(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Did you mean: %s?", strings.Join(names, ", "))
+}
+
+func autocompleteNames(text string, fill bool) tea.Cmd {
+	return func() tea.Msg {
+		if offline {
+			return suggestionsMsg{text: text, fill: fill, err: fmt.Errorf("name suggestions need Scryfall and are not available offline")}
+		}
+		names, err := api.Autocomplete(text)
+		return suggestionsMsg{text: text, names: names, fill: fill, err: err}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	telegramAPI          = "https://api.telegram.org/bot"
+	telegramPollTimeout  = 30
+	telegramMaxResults   = 50
+	telegramCacheSeconds = 300
+	telegramRetryDelay   = 5 * time.Second
+)
+
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+type telegramUpdate struct {
+	UpdateID    int                  `json:"update_id"`
+	InlineQuery *telegramInlineQuery `json:"inline_query"`
+}
+
+type telegramInlineQuery struct {
+	ID    string `json:"id"`
+	Query string `json:"query"`
+}
+
+type telegramPhotoResult struct {
+	Type         string `json:"type"`
+	ID           string `json:"id"`
+	PhotoURL     string `json:"photo_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Title        string `json:"title,omitempty"`
+	Caption      string `json:"caption,omitempty"`
+}
+
+type telegramBot struct {
+	token  string
+	client *http.Client
+	offset int
+}
+
+func runTelegram(args []string) error {
+	fs := flag.NewFlagSet("telegram", flag.ExitOnError)
+	token := fs.String("token", os.Getenv("TELEGRAM_BOT_TOKEN"), "bot token (defaults to $TELEGRAM_BOT_TOKEN)")
+	fs.Parse(args)
+
+	if *token == "" {
+		return fmt.Errorf("no bot token: set TELEGRAM_BOT_TOKEN or pass -token")
+	}
+
+	bot := &telegramBot{
+		token:  *token,
+		client: &http.Client{Timeout: (telegramPollTimeout + 10) * time.Second},
+	}
+
+	log.Printf("telegram bot listening for inline queries")
+	for {
+		updates, err := bot.getUpdates()
+		if err != nil {
+			log.Printf("telegram: %v", err)
+			time.Sleep(telegramRetryDelay)
+			continue
+		}
+
+		for _, update := range updates {
+			bot.offset = update.UpdateID + 1
+			if update.InlineQuery == nil {
+				continue
+			}
+			if err := bot.answerInlineQuery(update.InlineQuery); err != nil {
+				log.Printf("telegram: %v", err)
+			}
+		}
+	}
+}
+
+func (b *telegramBot) getUpdates() ([]telegramUpdate, error) {
+	params := url.Values{}
+	params.Add("offset", fmt.Sprint(b.offset))
+	params.Add("timeout", fmt.Sprint(telegramPollTimeout))
+	params.Add("allowed_updates", `["inline_query"]`)
+
+	var updates []telegramUpdate
+	if err := b.call("getUpdates", params, nil, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+func (b *telegramBot) answerInlineQuery(q *telegramInlineQuery) error {
+	results := []telegramPhotoResult{}
+
+	if q.Query != "" {
+		cards, err := fetchCards(q.Query, defaultSort)
+		if err != nil {
+			log.Printf("telegram: search %q: %v", q.Query, err)
+		}
+		for _, card := range cards {
+			if len(results) == telegramMaxResults {
+				break
+			}
+			photo, thumb := card.Image("normal"), card.Image("small")
+			if photo == "" {
+				continue
+			}
+			results = append(results, telegramPhotoResult{
+				Type:         "photo",
+				ID:           card.ID,
+				PhotoURL:     photo,
+				ThumbnailURL: thumb,
+				Title:        card.Name,
+				Caption:      fmt.Sprintf("%s %s — %s", card.Name, card.ManaCost, priceLabel(card.Prices)),
+			})
+		}
+	}
+
+	payload := map[string]any{
+		"inline_query_id": q.ID,
+		"results":         results,
+		"cache_time":      telegramCacheSeconds,
+	}
+	return b.call("answerInlineQuery", nil, payload, nil)
+}
+
+func (b *telegramBot) call(method string, params url.Values, payload any, out any) error {
+	reqURL := fmt.Sprintf("%s%s/%s", telegramAPI, b.token, method)
+	if params != nil {
+		reqURL = fmt.Sprintf("%s?%s", reqURL, params.Encode())
+	}
+
+	var resp *http.Response
+	var err error
+	if payload != nil {
+		encoded, encErr := json.Marshal(payload)
+		if encErr != nil {
+			return fmt.Errorf("failed to encode %s request: %w", method, encErr)
+		}
+		resp, err = b.client.Post(reqURL, "application/json", bytes.NewReader(encoded))
+	} else {
+		resp, err = b.client.Get(reqURL)
+	}
+	if err != nil {
+		// The request URL holds the bot token, so leave it out of the error.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("%s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+
+	var result telegramResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", method, err)
+	}
+	if !result.OK {
+		return fmt.Errorf("%s failed: %s", method, result.Description)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(result.Result, out); err != nil {
+			return fmt.Errorf("failed to parse %s result: %w", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	twitchIRCAddr    = "irc.chat.twitch.tv:6697"
+	twitchMaxMessage = 500
+)
+
+func runTwitch(args []string) error {
+	fs := flag.NewFlagSet("twitch", flag.ExitOnError)
+	nick := fs.String("nick", os.Getenv("TWITCH_NICK"), "bot account login (defaults to $TWITCH_NICK)")
+	token := fs.String("token", os.Getenv("TWITCH_OAUTH_TOKEN"), "chat OAuth token (defaults to $TWITCH_OAUTH_TOKEN)")
+	channels := fs.String("channels", "", "comma-separated list of channels to join")
+	cooldown := fs.Duration("cooldown", 30*time.Second, "minimum time between replies in a channel")
+	fs.Parse(args)
+
+	if *nick == "" || *token == "" {
+		return fmt.Errorf("no credentials: set TWITCH_NICK and TWITCH_OAUTH_TOKEN or pass -nick and -token")
+	}
+
+	joins := splitChannels(strings.ToLower(*channels))
+	if len(joins) == 0 {
+		return fmt.Errorf("no channels to join: pass -channels")
+	}
+
+	pass := *token
+	if !strings.HasPrefix(pass, "oauth:") {
+		pass = "oauth:" + pass
+	}
+
+	session := &ircSession{
+		name:       "twitch",
+		addr:       twitchIRCAddr,
+		useTLS:     true,
+		pass:       pass,
+		nick:       strings.ToLower(*nick),
+		channels:   joins,
+		maxMessage: twitchMaxMessage,
+		cooldown:   newBotCooldown(*cooldown),
+	}
+	return session.run()
+}